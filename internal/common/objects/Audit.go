@@ -0,0 +1,62 @@
+package objects
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+
+	"github.com/google/uuid"
+)
+
+type AuditClaimType int
+
+const (
+	ForcesClaim        AuditClaimType = iota // the forces an agent applied in a round
+	DirectionVoteClaim                       // the direction vote an agent cast in a round
+	RulerVoteClaim                           // the ballot an agent cast in the last election of a ruler held in a round
+)
+
+// a claim an agent pays the server to check against what really happened
+type AuditRequest struct {
+	Claim   AuditClaimType
+	Subject uuid.UUID        // the agent the claim is about
+	Round   int              // the round the claim is about
+	Forces  utils.Forces     // the forces claimed, for a ForcesClaim
+	VoteMap voting.IdVoteMap // the ballot claimed, for a vote claim
+}
+
+type AuditOutcome int
+
+const (
+	ClaimTrue         AuditOutcome = iota // the claim matches the records of the server
+	ClaimFalse                            // the claim doesn't match the records of the server
+	ClaimUnverifiable                     // the server has no record to check the claim against
+)
+
+func (o AuditOutcome) String() string {
+	switch o {
+	case ClaimTrue:
+		return "true"
+	case ClaimFalse:
+		return "false"
+	case ClaimUnverifiable:
+		return "unverifiable"
+	default:
+		return "unknown"
+	}
+}
+
+// asks the server whether the forces in the message are those the agent applied in the given round,
+// usually the round the message was sent in
+func AuditForcesMessage(msg ForcesMessage, round int) AuditRequest {
+	return AuditRequest{Claim: ForcesClaim, Subject: msg.AgentId, Round: round, Forces: msg.AgentForces}
+}
+
+// asks the server whether the sender of the message cast the direction vote it claims in the given round
+func AuditVoteLootboxDirectionMessage(msg VoteLootboxDirectionMessage, round int) AuditRequest {
+	return AuditRequest{Claim: DirectionVoteClaim, Subject: msg.GetSender().GetID(), Round: round, VoteMap: msg.VoteMap}
+}
+
+// asks the server whether the sender of the message cast the ruler vote it claims in the given round
+func AuditVoteRulerMessage(msg VoteRulerMessage, round int) AuditRequest {
+	return AuditRequest{Claim: RulerVoteClaim, Subject: msg.GetSender().GetID(), Round: round, VoteMap: msg.VoteMap}
+}
@@ -4,6 +4,7 @@ import (
 	utils "SOMAS2023/internal/common/utils"
 	voting "SOMAS2023/internal/common/voting"
 	"math"
+	"slices"
 
 	"math/rand"
 
@@ -35,6 +36,27 @@ type IBaseBiker interface {
 	// leader functions
 	DecideWeights(action utils.Action) map[uuid.UUID]float64 // decide on weights for various actions
 
+	// negotiator functions (called on the ruler, or the delegate, of a bike contesting a lootbox)
+	DecideNegotiationOffers(contested map[uuid.UUID][]uuid.UUID) []NegotiationOfferMessage // ** offers to make to the other bikes aiming for the same lootboxes
+	RespondToNegotiationOffer(offer NegotiationOfferMessage) bool                          // ** accept or reject an offer made to our bike
+
+	// energy and points trading functions
+	DecideEnergyTransfers() []EnergyTransferOffer                // ** energy the agent wants to give or lend to other agents
+	DecideEnergyTransferResponse(offer EnergyTransferOffer) bool // ** accept or reject energy offered by another agent
+	DecidePointsPurchases() []PointsPurchase                     // ** what the agent wants to spend its points on this round
+	DecideAudits() []AuditRequest                                // ** claims the agent pays energy to have checked by the server
+	DecideNoticeBoardPosts() []NoticeBoardPost                   // ** notices the agent wants to post, edit or remove on the notice board of its bike
+
+	// institutional functions
+	VoteGovernance() voting.GovernanceVote                 // ** vote on the governance of the bike in a constitutional vote
+	DecideVoteMethod(action utils.Action) utils.VoteMethod // ** the voting method the agent wants the bike to use for a decision
+	DecideRecall() bool                                    // ** petition for a vote of no confidence in the ruler of the bike
+	VoteRecall() bool                                      // ** vote in a vote of no confidence, true to remove the ruler
+	DecideCandidacy() bool                                 // ** whether to run in the scheduled election of the leader of the bike
+	CreateCampaignMessage() CampaignMessage                // ** the platform the agent announces to the riders when running for leader
+	ProposeRuleAmendments() []RuleAmendment                // ** changes the agent wants to make to the rules of the bike
+	VoteRuleAmendment(amendment RuleAmendment) bool        // ** vote on a change to the rules of the bike, true to make it
+
 	GetForces() utils.Forces        // returns forces for current round
 	GetColour() utils.Colour        // returns the colour of the lootbox that the agent is currently seeking
 	GetLocation() utils.Coordinates // gets the agent's location
@@ -46,6 +68,7 @@ type IBaseBiker interface {
 
 	SetBike(uuid.UUID)                     // sets the megaBikeID. this is either the id of the bike that the agent is on or the one that it's trying to join
 	SetForces(forces utils.Forces)         // sets the forces (to be updated in DecideForces())
+	SetColour(colour utils.Colour)         // called by server when the agent pays to seek another colour
 	UpdateColour(totColours utils.Colour)  // called if a box of the desired colour has been looted
 	UpdatePoints(pointGained int)          // called by server
 	UpdateEnergyLevel(energyLevel float64) // increase the energy level of the agent by the allocated lootbox share or decrease by expended energy
@@ -67,8 +90,17 @@ type IBaseBiker interface {
 	HandleVoteLootboxDirectionMessage(msg VoteLootboxDirectionMessage)
 	HandleVoteRulerMessage(msg VoteRulerMessage)
 	HandleVoteKickoutMessage(msg VoteKickoutMessage)
+	HandleNegotiationOfferMessage(msg NegotiationOfferMessage)
+	HandleCampaignMessage(msg CampaignMessage)
+	HandleAuditResultMessage(msg AuditResultMessage)
+	HandleDeliveryReceiptMessage(msg DeliveryReceiptMessage)
+	HandleGenericMessage(msg GenericMessage) // messages of the types teams define themselves, see MessageRegistry
 
 	GetAllMessages([]IBaseBiker) []messaging.IMessage[IBaseBiker]
+	GetMessagingPhase() utils.MessagingPhase      // the phase of the round GetAllMessages is called in
+	SetMessagingPhase(phase utils.MessagingPhase) // called by the server before each messaging phase
+	GetInbox() []InboxMessage                     // the messages delivered to the agent in the last utils.InboxRounds rounds, oldest first
+	ReceiveMessage(msg InboxMessage)              // called by the server when a message is delivered, before its handler
 }
 
 type BikerAction int
@@ -89,6 +121,9 @@ type BaseBiker struct {
 	gameState                        IGameState            // updated by the server at every round
 	reputation                       map[uuid.UUID]float64 // record reputation for other agents in float
 	GroupID                          int
+	messagingPhase                   utils.MessagingPhase // updated by the server before each messaging phase
+	inbox                            []InboxMessage
+	messageRegistry                  *MessageRegistry // the handlers of the generic messages the agent understands
 }
 
 func (bb *BaseBiker) GetEnergyLevel() float64 {
@@ -258,6 +293,10 @@ func (bb *BaseBiker) UpdateColour(totColours utils.Colour) {
 	bb.soughtColour = utils.Colour(rand.Intn(int(totColours)))
 }
 
+func (bb *BaseBiker) SetColour(colour utils.Colour) {
+	bb.soughtColour = colour
+}
+
 // update the points at the end of a round
 func (bb *BaseBiker) UpdatePoints(pointsGained int) {
 	bb.points += pointsGained
@@ -337,6 +376,31 @@ func (bb *BaseBiker) DecideGovernance() utils.Governance {
 	return utils.Democracy
 }
 
+// the default implementation votes for the governance the agent would choose when founding a bike
+func (bb *BaseBiker) VoteGovernance() voting.GovernanceVote {
+	return voting.GovernanceVote{bb.DecideGovernance(): 1.0}
+}
+
+// the default implementation always runs for leader
+func (bb *BaseBiker) DecideCandidacy() bool {
+	return true
+}
+
+// the default implementation is happy with the default voting method
+func (bb *BaseBiker) DecideVoteMethod(action utils.Action) utils.VoteMethod {
+	return utils.VoteAction
+}
+
+// the default implementation never petitions against the ruler
+func (bb *BaseBiker) DecideRecall() bool {
+	return false
+}
+
+// the default implementation keeps the ruler in power
+func (bb *BaseBiker) VoteRecall() bool {
+	return false
+}
+
 func (bb *BaseBiker) ResetPoints() {
 	bb.points = 0
 }
@@ -434,6 +498,74 @@ func (bb *BaseBiker) DecideDictatorAllocation() voting.IdVoteMap {
 	return distribution
 }
 
+// only called when the agent negotiates for its bike. contested maps each contested lootbox to the bikes aiming for it.
+// the default implementation proposes an even split to every other bike aiming for the same lootbox as our bike
+func (bb *BaseBiker) DecideNegotiationOffers(contested map[uuid.UUID][]uuid.UUID) []NegotiationOfferMessage {
+	offers := make([]NegotiationOfferMessage, 0)
+	for lootboxId, bikes := range contested {
+		if !slices.Contains(bikes, bb.GetBike()) {
+			continue
+		}
+		for _, bikeId := range bikes {
+			if bikeId == bb.GetBike() {
+				continue
+			}
+			offers = append(offers, bb.CreateNegotiationOfferMessage(lootboxId, bikeId, SplitProposal, 0.5, 0.0))
+		}
+	}
+	return offers
+}
+
+// only called when the agent negotiates for its bike
+// the default implementation accepts anything that leaves our bike with at least an even split
+func (bb *BaseBiker) RespondToNegotiationOffer(offer NegotiationOfferMessage) bool {
+	switch offer.OfferType {
+	case Yield:
+		return true
+	case SplitProposal:
+		return 1.0-offer.Share >= 0.5-utils.Epsilon
+	case SidePayment:
+		return offer.Payment > 0.0
+	default:
+		return false
+	}
+}
+
+// the default implementation doesn't give or lend any energy
+func (bb *BaseBiker) DecideEnergyTransfers() []EnergyTransferOffer {
+	return []EnergyTransferOffer{}
+}
+
+// the default implementation accepts gifts but doesn't take on any debt
+func (bb *BaseBiker) DecideEnergyTransferResponse(offer EnergyTransferOffer) bool {
+	return !offer.Loan
+}
+
+// the default implementation saves up all the points
+func (bb *BaseBiker) DecidePointsPurchases() []PointsPurchase {
+	return []PointsPurchase{}
+}
+
+// the default implementation takes every claim at face value
+func (bb *BaseBiker) DecideAudits() []AuditRequest {
+	return []AuditRequest{}
+}
+
+// the default implementation is happy with the rules as they are
+func (bb *BaseBiker) ProposeRuleAmendments() []RuleAmendment {
+	return []RuleAmendment{}
+}
+
+// the default implementation votes to keep the rules as they are
+func (bb *BaseBiker) VoteRuleAmendment(amendment RuleAmendment) bool {
+	return false
+}
+
+// the default implementation leaves the notice board alone
+func (bb *BaseBiker) DecideNoticeBoardPosts() []NoticeBoardPost {
+	return []NoticeBoardPost{}
+}
+
 // This function updates all the messages for that agent i.e. both sending and receiving.
 // And returns the new messages from other agents to your agent
 func (bb *BaseBiker) GetAllMessages([]IBaseBiker) []messaging.IMessage[IBaseBiker] {
@@ -556,6 +688,38 @@ func (bb *BaseBiker) CreateVotekickoutMessage() VoteKickoutMessage {
 	}
 }
 
+func (bb *BaseBiker) CreateNegotiationOfferMessage(lootboxId uuid.UUID, toBikeId uuid.UUID, offerType NegotiationOfferType, share float64, payment float64) NegotiationOfferMessage {
+	// The offer is addressed to the riders of the other bike, but only their negotiator will be asked to respond
+	var recipients []IBaseBiker
+	if bike, ok := bb.gameState.GetMegaBikes()[toBikeId]; ok {
+		recipients = bike.GetAgents()
+	}
+	return NegotiationOfferMessage{
+		BaseMessage: messaging.CreateMessage[IBaseBiker](bb, recipients),
+		OfferType:   offerType,
+		LootboxId:   lootboxId,
+		FromBikeId:  bb.GetBike(),
+		ToBikeId:    toBikeId,
+		Share:       share,
+		Payment:     payment,
+	}
+}
+
+func (bb *BaseBiker) CreateCampaignMessage() CampaignMessage {
+	// Currently this returns a campaign promising equal weights to every rider
+	// For team's agent, add your own logic to decide on a platform
+	fellowBikers := bb.GetFellowBikers()
+	weights := make(map[uuid.UUID]float64, len(fellowBikers))
+	for _, fellowBiker := range fellowBikers {
+		weights[fellowBiker.GetID()] = 1.0
+	}
+	return CampaignMessage{
+		BaseMessage: messaging.CreateMessage[IBaseBiker](bb, fellowBikers),
+		Weights:     weights,
+		Statement:   "",
+	}
+}
+
 func (bb *BaseBiker) HandleKickoutMessage(msg KickoutAgentMessage) {
 	// Team's agent should implement logic for handling other biker messages that were sent to them.
 
@@ -632,6 +796,75 @@ func (bb *BaseBiker) HandleVoteKickoutMessage(msg VoteKickoutMessage) {
 	// voteMap := msg.VoteMap
 }
 
+func (bb *BaseBiker) HandleNegotiationOfferMessage(msg NegotiationOfferMessage) {
+	// Team's agent should implement logic for handling other biker messages that were sent to them.
+	// Offers received here are informal: only offers made during the negotiation session are binding.
+
+	// sender := msg.BaseMessage.GetSender()
+	// lootboxId := msg.LootboxId
+	// offerType := msg.OfferType
+}
+
+func (bb *BaseBiker) HandleCampaignMessage(msg CampaignMessage) {
+	// Team's agent should implement logic for handling other biker messages that were sent to them.
+
+	// candidate := msg.BaseMessage.GetSender()
+	// weights := msg.Weights
+}
+
+func (bb *BaseBiker) HandleAuditResultMessage(msg AuditResultMessage) {
+	// Team's agent should implement logic for handling the results of the audits it asked for.
+
+	// subject := msg.Request.Subject
+	// outcome := msg.Outcome
+}
+
+func (bb *BaseBiker) HandleDeliveryReceiptMessage(msg DeliveryReceiptMessage) {
+	// Team's agent should implement logic for handling the messages the server couldn't deliver.
+
+	// recipient := msg.Recipient
+	// failure := msg.Failure
+}
+
+// by default the generic messages go to the handlers registered with the agent's registry, those of other types are ignored
+func (bb *BaseBiker) HandleGenericMessage(msg GenericMessage) {
+	if bb.messageRegistry != nil {
+		bb.messageRegistry.Dispatch(msg)
+	}
+}
+
+// the registry the agent's handlers of generic messages go in
+func (bb *BaseBiker) GetMessageRegistry() *MessageRegistry {
+	if bb.messageRegistry == nil {
+		bb.messageRegistry = NewMessageRegistry()
+	}
+	return bb.messageRegistry
+}
+
+func (bb *BaseBiker) GetMessagingPhase() utils.MessagingPhase {
+	return bb.messagingPhase
+}
+
+func (bb *BaseBiker) SetMessagingPhase(phase utils.MessagingPhase) {
+	bb.messagingPhase = phase
+}
+
+func (bb *BaseBiker) GetInbox() []InboxMessage {
+	return bb.inbox
+}
+
+// keeps the message, and forgets the ones delivered more than utils.InboxRounds rounds ago
+func (bb *BaseBiker) ReceiveMessage(msg InboxMessage) {
+	kept := 0
+	for _, old := range bb.inbox {
+		if old.Round > msg.Round-utils.InboxRounds {
+			bb.inbox[kept] = old
+			kept++
+		}
+	}
+	bb.inbox = append(bb.inbox[:kept], msg)
+}
+
 // this function is going to be called by the server to instantiate bikers in the MVP
 func GetIBaseBiker(totColours utils.Colour, bikeId uuid.UUID) IBaseBiker {
 	return &BaseBiker{
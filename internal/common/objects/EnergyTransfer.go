@@ -0,0 +1,46 @@
+package objects
+
+import "github.com/google/uuid"
+
+// an offer to give (or lend) energy to another agent. It only goes through if the recipient accepts it
+type EnergyTransferOffer struct {
+	From         uuid.UUID // the agent giving the energy
+	To           uuid.UUID // the agent receiving the energy
+	Amount       float64   // the energy transferred
+	Loan         bool      // if true, Amount*(1+InterestRate) must be repaid after Term rounds
+	InterestRate float64   // interest on the loan (e.g. 0.1 for 10%)
+	Term         int       // number of rounds after which the loan is due
+}
+
+type EnergyTransactionType int
+
+const (
+	GiftTransfer  EnergyTransactionType = iota // energy given away
+	LoanTransfer                               // energy lent
+	LoanRepayment                              // energy repaid to a lender
+	LoanDefault                                // energy owed to a lender that couldn't be repaid
+)
+
+// an entry of the energy ledger kept by the server
+type EnergyTransaction struct {
+	Round  int                   `json:"round"`
+	Type   EnergyTransactionType `json:"type"`
+	From   uuid.UUID             `json:"from"`
+	To     uuid.UUID             `json:"to"`
+	Amount float64               `json:"amount"`
+}
+
+// a loan that hasn't been repaid yet
+type EnergyLoan struct {
+	ID           uuid.UUID `json:"id"`
+	Lender       uuid.UUID `json:"lender"`
+	Borrower     uuid.UUID `json:"borrower"`
+	Principal    float64   `json:"principal"`
+	InterestRate float64   `json:"interest_rate"`
+	DueRound     int       `json:"due_round"`
+}
+
+// the energy the borrower has to repay when the loan is due
+func (loan EnergyLoan) AmountOwed() float64 {
+	return loan.Principal * (1.0 + loan.InterestRate)
+}
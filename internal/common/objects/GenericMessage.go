@@ -0,0 +1,73 @@
+package objects
+
+import (
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+)
+
+// the kind of a generic message. Teams pick their own, e.g. "team3/trade offer"
+type MessageType string
+
+// a message of a kind the shared interface doesn't know of. Its payload can be anything, down to a team's own structs
+type GenericMessage struct {
+	messaging.BaseMessage[IBaseBiker]
+	Type    MessageType
+	Payload any
+}
+
+// handles the generic messages of one type
+type MessageHandler func(msg GenericMessage)
+
+// the handlers an agent has for the types of generic messages it understands
+type MessageRegistry struct {
+	handlers map[MessageType]MessageHandler
+}
+
+func NewMessageRegistry() *MessageRegistry {
+	return &MessageRegistry{handlers: make(map[MessageType]MessageHandler)}
+}
+
+// sets the handler of the messages of the type, replacing the one there was
+func (r *MessageRegistry) Register(messageType MessageType, handler MessageHandler) {
+	r.handlers[messageType] = handler
+}
+
+func (r *MessageRegistry) Unregister(messageType MessageType) {
+	delete(r.handlers, messageType)
+}
+
+func (r *MessageRegistry) IsRegistered(messageType MessageType) bool {
+	_, ok := r.handlers[messageType]
+	return ok
+}
+
+// passes the message to the handler of its type. Returns false if there is none, the message is then ignored
+func (r *MessageRegistry) Dispatch(msg GenericMessage) bool {
+	handler, ok := r.handlers[msg.Type]
+	if !ok {
+		return false
+	}
+	handler(msg)
+	return true
+}
+
+// registers a handler for messages of the type whose payload is a T. Messages of the type with another payload are ignored
+func RegisterHandler[T any](r *MessageRegistry, messageType MessageType, handler func(msg GenericMessage, payload T)) {
+	r.Register(messageType, func(msg GenericMessage) {
+		if payload, ok := msg.Payload.(T); ok {
+			handler(msg, payload)
+		}
+	})
+}
+
+// builds a generic message of the type carrying the payload
+func NewGenericMessage(sender IBaseBiker, recipients []IBaseBiker, messageType MessageType, payload any) GenericMessage {
+	return GenericMessage{
+		BaseMessage: messaging.CreateMessage[IBaseBiker](sender, recipients),
+		Type:        messageType,
+		Payload:     payload,
+	}
+}
+
+func (msg GenericMessage) InvokeMessageHandler(agent IBaseBiker) {
+	agent.HandleGenericMessage(msg)
+}
@@ -10,4 +10,7 @@ type IGameState interface {
 	GetMegaBikes() map[uuid.UUID]IMegaBike
 	GetAgents() map[uuid.UUID]IBaseBiker
 	GetAudi() IAudi
+	GetEnergyLedger() []EnergyTransaction // every energy transfer of the current game
+	GetOutstandingLoans() []EnergyLoan
+	GetPointsLedger() []PointsTransaction // every points purchase of the current game
 }
@@ -0,0 +1,40 @@
+package objects
+
+import (
+	"SOMAS2023/internal/common/utils"
+
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+	"github.com/google/uuid"
+)
+
+// a message as it reached an agent, with when it was sent and when it was delivered
+type InboxMessage struct {
+	Message   messaging.IMessage[IBaseBiker]
+	Sender    uuid.UUID
+	SentRound int
+	SentPhase utils.MessagingPhase
+	Round     int // the round it was delivered in
+	Phase     utils.MessagingPhase
+}
+
+// why the server couldn't deliver a message
+type DeliveryFailure int
+
+const (
+	UnknownRecipient    DeliveryFailure = iota // the recipient isn't alive, or never was
+	RecipientDied                              // the recipient died before the message arrived
+	RecipientOutOfRange                        // the recipient is beyond the range of messages, see utils.MessagingLimits
+)
+
+func (f DeliveryFailure) String() string {
+	switch f {
+	case UnknownRecipient:
+		return "unknown recipient"
+	case RecipientDied:
+		return "recipient died"
+	case RecipientOutOfRange:
+		return "recipient out of range"
+	default:
+		return "unknown"
+	}
+}
@@ -7,6 +7,7 @@ import (
 type ILootBox interface {
 	IPhysicsObject
 	GetTotalResources() float64
+	GetResourceRange() (float64, float64) // the lowest and highest total loot the lootbox could hold
 	GetColour() utils.Colour
 }
 
@@ -30,6 +31,11 @@ func (lb *LootBox) GetTotalResources() float64 {
 	return lb.totalLoot
 }
 
+// the loot of a lootbox is known exactly, so the range is a single value
+func (lb *LootBox) GetResourceRange() (float64, float64) {
+	return lb.totalLoot, lb.totalLoot
+}
+
 // GetColour returns the color of the BikerAgent.
 func (lb *LootBox) GetColour() utils.Colour {
 	return lb.colour
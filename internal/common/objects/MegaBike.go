@@ -2,6 +2,7 @@ package objects
 
 import (
 	utils "SOMAS2023/internal/common/utils"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +18,16 @@ type IMegaBike interface {
 	GetRuler() uuid.UUID
 	SetGovernance(governance utils.Governance)
 	SetRuler(ruler uuid.UUID)
+	GetCouncil() []uuid.UUID
+	SetCouncil(council []uuid.UUID)
+	GetVoteMethod(action utils.Action) utils.VoteMethod
+	SetVoteMethod(action utils.Action, method utils.VoteMethod)
+	GetWeightingPolicy() utils.WeightingPolicy
+	SetWeightingPolicy(policy utils.WeightingPolicy)
+	GetNotices() []Notice
+	SetNotices(notices []Notice)
+	GetRules() []Rule
+	SetRules(rules []Rule)
 }
 
 // MegaBike will have the following forces
@@ -26,6 +37,11 @@ type MegaBike struct {
 	kickedOutCount int
 	governance     utils.Governance
 	ruler          uuid.UUID
+	council        []uuid.UUID
+	voteMethods    map[utils.Action]utils.VoteMethod
+	weighting      utils.WeightingPolicy
+	notices        []Notice
+	rules          []Rule
 }
 
 // GetMegaBike is a constructor for MegaBike that initializes it with a new UUID and default position.
@@ -34,6 +50,11 @@ func GetMegaBike() *MegaBike {
 		PhysicsObject: GetPhysicsObject(utils.MassBike),
 		governance:    utils.Democracy,
 		ruler:         uuid.Nil,
+		council:       make([]uuid.UUID, 0),
+		voteMethods:   make(map[utils.Action]utils.VoteMethod),
+		weighting:     utils.DemocracyWeighting,
+		notices:       make([]Notice, 0),
+		rules:         make([]Rule, 0),
 	}
 }
 
@@ -120,14 +141,14 @@ func (mb *MegaBike) GetKickedOutCount() int {
 	return mb.kickedOutCount
 }
 
-// only called for level 0 and level 1
+// only called for governances where the riders vote, each vote counts with the weight of the voter
 func (mb *MegaBike) KickOutAgent(weights map[uuid.UUID]float64) []uuid.UUID {
 	voteCount := make(map[uuid.UUID]float64)
 	// Count votes for each agent
 	for _, agent := range mb.agents {
 		agentVotes := agent.VoteForKickout() // Assuming this now returns map[uuid.UUID]int
 		for agentID, votes := range agentVotes {
-			agentWeight := weights[agentID]
+			agentWeight := weights[agent.GetID()]
 			if val, ok := voteCount[agentID]; ok {
 				voteCount[agentID] = float64(val) + agentWeight*float64(votes)
 			} else {
@@ -164,3 +185,50 @@ func (mb *MegaBike) SetGovernance(governance utils.Governance) {
 func (mb *MegaBike) SetRuler(ruler uuid.UUID) {
 	mb.ruler = ruler
 }
+
+func (mb *MegaBike) GetCouncil() []uuid.UUID {
+	return slices.Clone(mb.council)
+}
+
+func (mb *MegaBike) SetCouncil(council []uuid.UUID) {
+	mb.council = slices.Clone(council)
+}
+
+// returns the voting method the bike uses for a decision (utils.VoteAction unless the riders chose another one)
+func (mb *MegaBike) GetVoteMethod(action utils.Action) utils.VoteMethod {
+	if method, ok := mb.voteMethods[action]; ok {
+		return method
+	}
+	return utils.VoteAction
+}
+
+func (mb *MegaBike) SetVoteMethod(action utils.Action, method utils.VoteMethod) {
+	mb.voteMethods[action] = method
+}
+
+// returns how the server weighs the votes of the riders when the bike is a democracy
+func (mb *MegaBike) GetWeightingPolicy() utils.WeightingPolicy {
+	return mb.weighting
+}
+
+func (mb *MegaBike) SetWeightingPolicy(policy utils.WeightingPolicy) {
+	mb.weighting = policy
+}
+
+// returns the notice board of the bike, oldest notice first
+func (mb *MegaBike) GetNotices() []Notice {
+	return slices.Clone(mb.notices)
+}
+
+func (mb *MegaBike) SetNotices(notices []Notice) {
+	mb.notices = slices.Clone(notices)
+}
+
+// returns the rules in force on the bike, in the order they were enacted
+func (mb *MegaBike) GetRules() []Rule {
+	return slices.Clone(mb.rules)
+}
+
+func (mb *MegaBike) SetRules(rules []Rule) {
+	mb.rules = slices.Clone(rules)
+}
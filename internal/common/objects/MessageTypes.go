@@ -74,6 +74,48 @@ type VoteKickoutMessage struct {
 	VoteMap map[uuid.UUID]int // the vote map that you voted for (if you are telling the truth)
 }
 
+type NegotiationOfferType int
+
+const (
+	Claim         NegotiationOfferType = iota // the proposing bike wants the whole lootbox
+	Yield                                     // the proposing bike gives up the lootbox to the other bike
+	SplitProposal                             // the lootbox is split according to Share
+	SidePayment                               // the other bike yields in exchange for Payment energy
+)
+
+// "If we both reach this lootbox, this is how I propose we share it"
+type NegotiationOfferMessage struct {
+	messaging.BaseMessage[IBaseBiker]
+	OfferType  NegotiationOfferType
+	LootboxId  uuid.UUID // the contested lootbox
+	FromBikeId uuid.UUID // the bike making the offer
+	ToBikeId   uuid.UUID // the bike the offer is made to
+	Share      float64   // for SplitProposal: fraction of the loot going to the proposing bike (0-1)
+	Payment    float64   // for SidePayment: energy paid by the proposer to the riders of the other bike
+}
+
+// "Elect me as leader, this is how I will lead the bike"
+type CampaignMessage struct {
+	messaging.BaseMessage[IBaseBiker]
+	Weights   map[uuid.UUID]float64 // the weights the candidate promises to give each rider if elected
+	Statement string                // anything else the candidate wants to promise
+}
+
+// "The server checked this claim for you". Sent by the server on behalf of the agent that asked for the audit
+type AuditResultMessage struct {
+	messaging.BaseMessage[IBaseBiker]
+	Request AuditRequest
+	Outcome AuditOutcome
+}
+
+// "Your message didn't reach this agent". Sent by the server on behalf of the sender of an undeliverable message
+type DeliveryReceiptMessage struct {
+	messaging.BaseMessage[IBaseBiker]
+	Recipient uuid.UUID                      // the agent the message was meant for
+	Message   messaging.IMessage[IBaseBiker] // the message that wasn't delivered
+	Failure   DeliveryFailure
+}
+
 func (msg ReputationOfAgentMessage) InvokeMessageHandler(agent IBaseBiker) {
 	agent.HandleReputationMessage(msg)
 }
@@ -113,3 +155,19 @@ func (msg VoteRulerMessage) InvokeMessageHandler(agent IBaseBiker) {
 func (msg VoteKickoutMessage) InvokeMessageHandler(agent IBaseBiker) {
 	agent.HandleVoteKickoutMessage(msg)
 }
+
+func (msg NegotiationOfferMessage) InvokeMessageHandler(agent IBaseBiker) {
+	agent.HandleNegotiationOfferMessage(msg)
+}
+
+func (msg CampaignMessage) InvokeMessageHandler(agent IBaseBiker) {
+	agent.HandleCampaignMessage(msg)
+}
+
+func (msg AuditResultMessage) InvokeMessageHandler(agent IBaseBiker) {
+	agent.HandleAuditResultMessage(msg)
+}
+
+func (msg DeliveryReceiptMessage) InvokeMessageHandler(agent IBaseBiker) {
+	agent.HandleDeliveryReceiptMessage(msg)
+}
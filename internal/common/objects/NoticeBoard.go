@@ -0,0 +1,58 @@
+package objects
+
+import (
+	"github.com/google/uuid"
+)
+
+type NoticeKind int
+
+const (
+	ProposalNotice   NoticeKind = iota // something the author would like the riders to do
+	PlanNotice                         // what the bike is going to do, e.g. the lootbox it heads for and the route there
+	CommitmentNotice                   // something the author promises to do
+	RuleNotice                         // a rule the riders are expected to follow
+)
+
+func (k NoticeKind) String() string {
+	switch k {
+	case ProposalNotice:
+		return "proposal"
+	case PlanNotice:
+		return "plan"
+	case CommitmentNotice:
+		return "commitment"
+	case RuleNotice:
+		return "rule"
+	default:
+		return "unknown"
+	}
+}
+
+// a notice on the notice board of a bike
+type Notice struct {
+	ID          uuid.UUID  `json:"id"`
+	Author      uuid.UUID  `json:"author"`
+	Kind        NoticeKind `json:"kind"`
+	Text        string     `json:"text"`
+	Target      uuid.UUID  `json:"target"` // the lootbox the notice is about, if any
+	Round       int        `json:"round"`  // the round it was posted in
+	Editor      uuid.UUID  `json:"editor"` // the last rider to edit it, the author until then
+	EditedRound int        `json:"edited_round"`
+}
+
+type NoticeBoardAction int
+
+const (
+	PostNotice NoticeBoardAction = iota
+	EditNotice
+	RemoveNotice
+)
+
+// a change a rider wants to make to the notice board of its bike
+type NoticeBoardPost struct {
+	Action   NoticeBoardAction
+	NoticeID uuid.UUID // the notice to edit or remove
+	Kind     NoticeKind
+	Text     string
+	Target   uuid.UUID
+}
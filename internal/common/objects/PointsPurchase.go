@@ -0,0 +1,32 @@
+package objects
+
+import (
+	"SOMAS2023/internal/common/utils"
+
+	"github.com/google/uuid"
+)
+
+type PointsPurchaseType int
+
+const (
+	VoteWeightPurchase   PointsPurchaseType = iota // extra vote weight on the agent's bike for the current round
+	BribePurchase                                  // points given to the ruler of the agent's bike
+	BikeEntryPurchase                              // join the bike the agent is trying to get on without a vote, even if it's full
+	ColourChangePurchase                           // change the colour of the lootboxes the agent is seeking
+)
+
+// something an agent wants to spend its points on
+type PointsPurchase struct {
+	Type   PointsPurchaseType
+	Points int          // points to spend on vote weight or on a bribe (the other purchases have a fixed price)
+	Colour utils.Colour // the new colour for a ColourChangePurchase
+}
+
+// an entry of the points ledger kept by the server. To is nil if the points were spent rather than given to an agent
+type PointsTransaction struct {
+	Round  int                `json:"round"`
+	Type   PointsPurchaseType `json:"type"`
+	From   uuid.UUID          `json:"from"`
+	To     uuid.UUID          `json:"to"`
+	Points int                `json:"points"`
+}
@@ -0,0 +1,63 @@
+package objects
+
+import (
+	"github.com/google/uuid"
+)
+
+type RuleKind int
+
+const (
+	MinimumPedalRule     RuleKind = iota // riders must pedal with a force of at least Value, or pay Fine
+	EffortShareRule                      // the loot is split in proportion to the energy the riders spent pedalling the bike
+	ViolationKickoutRule                 // riders that broke the rules Value times are kicked off the bike
+)
+
+func (k RuleKind) String() string {
+	switch k {
+	case MinimumPedalRule:
+		return "minimum pedal"
+	case EffortShareRule:
+		return "effort share"
+	case ViolationKickoutRule:
+		return "kickout after violations"
+	default:
+		return "unknown"
+	}
+}
+
+// a rule the riders of a bike enacted, enforced by the server
+type Rule struct {
+	ID      uuid.UUID `json:"id"`
+	Kind    RuleKind  `json:"kind"`
+	Value   float64   `json:"value"`
+	Fine    float64   `json:"fine"`    // energy taken from a rider each time it breaks the rule
+	Enacted int       `json:"enacted"` // the round the rule was enacted, or last amended, in
+}
+
+type AmendmentAction int
+
+const (
+	EnactRule AmendmentAction = iota
+	AmendRule                 // replaces the rule with the same ID
+	RepealRule
+)
+
+func (a AmendmentAction) String() string {
+	switch a {
+	case EnactRule:
+		return "enact"
+	case AmendRule:
+		return "amend"
+	case RepealRule:
+		return "repeal"
+	default:
+		return "unknown"
+	}
+}
+
+// a change to the rules of a bike proposed by one of its riders
+type RuleAmendment struct {
+	Action   AmendmentAction
+	Rule     Rule      // the rule to enact, or the rule to amend or repeal (by its ID)
+	Proposer uuid.UUID // set by the server
+}
@@ -203,3 +203,32 @@ func TestKickOutAgent(t *testing.T) {
 		}
 	}
 }
+
+func TestKickOutVotesCountWithTheVoterWeight(t *testing.T) {
+	mb := objects.GetMegaBike()
+	heavy := NewMockBiker()
+	light1 := NewMockBiker()
+	light2 := NewMockBiker()
+	mb.AddAgent(heavy)
+	mb.AddAgent(light1)
+	mb.AddAgent(light2)
+
+	// the heavy voter alone outweighs the others
+	weights := map[uuid.UUID]float64{
+		heavy.GetID():  2.0,
+		light1.GetID(): 0.0,
+		light2.GetID(): 0.0,
+	}
+	heavy.VoteMap[light2.GetID()] = 1
+	light1.VoteMap[heavy.GetID()] = 1
+	light2.VoteMap[heavy.GetID()] = 1
+
+	kickedOutAgents := mb.KickOutAgent(weights)
+
+	if len(kickedOutAgents) != 1 {
+		t.Fatalf("KickOutAgent kicked out %d agents; want 1", len(kickedOutAgents))
+	}
+	if kickedOutAgents[0] != light2.GetID() {
+		t.Errorf("KickOutAgent kicked out incorrect agent: got %v, want %v", kickedOutAgents[0], light2.GetID())
+	}
+}
@@ -127,3 +127,47 @@ func TestBaseBikerForcesMessaging(t *testing.T) {
 		t.Errorf("Expected both biker1 and biker2 to store 5 past forces, but got biker1=%d and biker2=%d", len(biker1.OtherBikerForces), len(biker2.OtherBikerForces))
 	}
 }
+
+func TestBaseBikerInbox(t *testing.T) {
+	biker := NewExtendedBaseBiker(uuid.New())
+	for round := 1; round <= utils.InboxRounds+1; round++ {
+		biker.ReceiveMessage(obj.InboxMessage{Sender: uuid.New(), Round: round, Phase: utils.EndOfRound})
+	}
+	// the message delivered in the first round is forgotten
+	inbox := biker.GetInbox()
+	if len(inbox) != utils.InboxRounds || inbox[0].Round != 2 {
+		t.Errorf("Expected the inbox to keep the messages of the last %d rounds, but got %d messages starting in round %d", utils.InboxRounds, len(inbox), inbox[0].Round)
+	}
+}
+
+type tradeOffer struct {
+	Energy float64
+	Points int
+}
+
+func TestGenericMessageRegistry(t *testing.T) {
+	sender := NewExtendedBaseBiker(uuid.New())
+	receiver := NewExtendedBaseBiker(uuid.New())
+	offers := make([]tradeOffer, 0)
+	obj.RegisterHandler(receiver.GetMessageRegistry(), "test/trade offer", func(msg obj.GenericMessage, offer tradeOffer) {
+		offers = append(offers, offer)
+	})
+
+	recipients := []obj.IBaseBiker{receiver}
+	messages := []obj.GenericMessage{
+		obj.NewGenericMessage(sender, recipients, "test/trade offer", tradeOffer{Energy: 0.1, Points: 2}),
+		// a payload of the wrong type and a type nobody registered are ignored
+		obj.NewGenericMessage(sender, recipients, "test/trade offer", "0.1 for 2"),
+		obj.NewGenericMessage(sender, recipients, "test/gossip", tradeOffer{}),
+	}
+	for _, msg := range messages {
+		msg.InvokeMessageHandler(receiver)
+	}
+
+	if len(offers) != 1 || offers[0].Points != 2 || offers[0].Energy != 0.1 {
+		t.Errorf("Expected the receiver to handle the one well formed trade offer, but got %v", offers)
+	}
+	if receiver.GetMessageRegistry().Dispatch(messages[2]) {
+		t.Errorf("Expected a message of an unregistered type not to be dispatched")
+	}
+}
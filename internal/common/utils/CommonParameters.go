@@ -38,11 +38,108 @@ const LimboEnergyPenalty float64 = -0.25 // amount of energy lost per round when
 const DeliberativeDemocracyPenalty float64 = 0.05 // amount of energy lost per vote in a deliberative democracy
 const LeadershipDemocracyPenalty float64 = 0.025  // amount of energy lost per vote in a leadership democracy
 
+/*
+Negotiation Parameters
+*/
+const NegotiationRounds = 3 // max number of offer rounds bikes contesting a lootbox get to reach an agreement
+
+/*
+Constitutional Voting Parameters
+*/
+const ConstitutionalVotePeriod = 10 // rounds between constitutional votes on each bike
+const GovernanceChangeCooldown = 20 // rounds after a change of governance before a bike can hold another constitutional vote
+
+// share of the riders that must vote for a governance to switch to it
+const DemocracySupermajority float64 = 0.5
+const LeadershipSupermajority float64 = 2.0 / 3.0
+const DictatorshipSupermajority float64 = 0.75
+const RotatingLeadershipSupermajority float64 = 0.5
+const SortitionSupermajority float64 = 0.5
+const CouncilSupermajority float64 = 0.5
+
+/*
+Leadership Election Parameters
+*/
+const LeaderTermLength = 15 // rounds a leader serves before the bike holds a new election
+const LeaderTermLimit = 2   // consecutive terms a leader can serve
+
+const RotationTermLength = 5 // rounds a rotating or sortition leader serves before the next one takes over
+const CouncilSize = 3        // members elected to the council of a bike
+
+/*
+No Confidence Parameters
+*/
+const RecallPetitionCost float64 = 0.05          // energy paid by each rider petitioning for a vote of no confidence
+const RecallQuorum float64 = 0.25                // share of the riders that must petition for the vote to be held
+const LeadershipRecallThreshold float64 = 0.5    // share of the riders that must vote to remove a leader
+const DictatorshipRecallThreshold float64 = 0.75 // share of the riders that must vote to overthrow a dictator
+
+/*
+Rules
+*/
+const RuleAmendmentCost float64 = 0.01    // energy paid by a rider for each change it proposes to the rules of its bike
+const RuleAmendmentMajority float64 = 0.5 // share of the (weighted) vote a change to the rules needs to pass
+const MaxRules int = 5                    // the most rules a bike can have in force
+
 /*
 Resources - Points and Energy
 */
 const PointsFromSameColouredLootBox = 5.0
 
+const VoteWeightPerPoint float64 = 0.1 // extra vote weight bought with each point
+const BikeEntryCost = 10               // points paid to the riders of a bike to join it without a vote
+const MaxPaidSeats = 2                 // seats a bike can have on top of BikersOnBike for agents that paid to join it
+const ColourChangeCost = 5             // points paid to change the colour of the lootboxes sought
+
+/*
+Colour Change Policy
+*/
+// how the server picks the new colour an agent seeks after looting a box of its colour
+type ColourPolicy int
+
+const (
+	RandomColour   ColourPolicy = iota // the agent picks a new colour through UpdateColour
+	NearestColour                      // the colour of the nearest remaining lootbox of a different colour
+	RotatingColour                     // the next colour in the list of colours
+)
+
+const ColourChangePolicy ColourPolicy = RandomColour
+
+/*
+Observability
+*/
+// how much of the game state the server shows each agent
+type Observability int
+
+const (
+	FullObservability    Observability = iota // every agent sees the whole game state
+	PartialObservability                      // agents see their fellow riders, some of the agents within VisionRadius and nobody beyond
+)
+
+const GameStateObservability Observability = FullObservability
+
+const VisionRadius float64 = 20.0       // distance up to which agents see the agents on other bikes
+const ObservedEnergyNoise float64 = 0.1 // standard deviation of the noise on the energy of agents seen on other bikes
+const ObservationSeed int64 = 2023      // seed of the random number generator of the noise, reset every game
+
+// how accurately the agents sense the game state, whether they see all of it or not
+type SensorModel struct {
+	PositionNoise float64 // standard deviation of the gaussian noise on the positions of other bikes and of the Audi
+	LootBoxRange  float64 // width of the range the resources of a lootbox are reported in, 0 for the exact value
+	ForceDelay    int     // number of rounds late the forces of fellow riders are observed
+}
+
+const PositionNoise float64 = 0.0 // default SensorModel
+const LootBoxRange float64 = 0.0
+const ForceDelay int = 0
+
+/*
+Notice Boards
+*/
+const NoticeBoardSize int = 10               // the most notices the notice board of a bike holds
+const NoticeLength int = 280                 // the most characters in the text of a notice
+const NoticesVisibleToApplicants bool = true // whether the agents asking to join a bike can read its notice board
+
 /*
 Audi Behavior
 */
@@ -53,15 +150,124 @@ const AudiRemovesMegaBike bool = false
 /*
 Voting Method Choice
 */
-type voteMethods int
+type VoteMethod int
 
 const (
-	PLURALITY voteMethods = iota
+	PLURALITY VoteMethod = iota
 	RUNOFF
 	BORDACOUNT
 	INSTANTRUNOFF
 	APPROVAL
 	COPELANDSCORING
+	SCHULZE
+	RANKEDPAIRS
+	KEMENYYOUNG
+	SCORE
+	STAR
+	NumOfVoteMethods // add a sentinel for counting the number of voting methods
+)
+
+const VoteAction VoteMethod = PLURALITY // the voting method bikes use until their riders choose another one
+
+const VoteMethodMajority float64 = 0.5 // share of the riders that must prefer a voting method for a bike to switch to it
+
+const InvalidBallotPenalty float64 = 0.05 // energy lost by an agent whose ballot, proposal or weighting had to be sanitised
+
+const AnalyseVotes bool = false     // whether the server checks every direction vote and election for strategic voting
+const AnalysisMaxCandidates int = 4 // with at most this many candidates the analysis tries every ranking as a misreport
+
+const KemenyYoungMaxCandidates int = 7 // Kemeny-Young tries every ranking of the candidates, with more candidates Schulze is used instead
+const ScoreVotingMax int = 5           // the highest score a voter can give a candidate in score and STAR voting
+
+// how the loot of a bike is split between its riders when they vote on the allocation
+type AllocationMethod int
+
+const (
+	CUMULATIVE  AllocationMethod = iota // every rider gets the weighted average of the shares voted for them
+	DHONDT                              // the shares are apportioned with the D'Hondt method
+	SAINTELAGUE                         // the shares are apportioned with the Sainte-Laguë method
+)
+
+const AllocationAction AllocationMethod = CUMULATIVE
+
+const AllocationSeats int = 100 // the number of equal parts the loot is split into by the apportionment methods
+
+/*
+Tie Breaking
+*/
+// how a vote picks between candidates with equal scores
+type TieBreak int
+
+const (
+	RandomTieBreak    TieBreak = iota // a draw from a random number generator seeded with TieBreakSeed
+	LowestIDTieBreak                  // the candidate with the lowest UUID
+	IncumbentTieBreak                 // the current ruler or direction of the bike, then the lowest UUID
+	SeniorityTieBreak                 // the rider who has been on the bike the longest, then the lowest UUID
 )
 
-const VoteAction voteMethods = PLURALITY
+const TieBreakRule TieBreak = LowestIDTieBreak
+
+const TieBreakSeed int64 = 2023 // seed of the random number generator used by RandomTieBreak, reset every game
+
+/*
+Vote Weighting
+*/
+// how the server weighs the votes of the riders of a democratic bike
+type WeightingPolicy int
+
+const (
+	EqualWeighting        WeightingPolicy = iota // every rider's vote counts the same
+	ReputationWeighting                          // riders weigh as much as the average reputation their fellow riders give them
+	SeniorityWeighting                           // riders weigh as much as the number of rounds they have been on the bike
+	ContributionWeighting                        // riders weigh as much as the energy they spent pedalling the bike
+)
+
+const DemocracyWeighting WeightingPolicy = EqualWeighting // the policy of new bikes
+
+/*
+Audits
+*/
+const AuditCost float64 = 0.02 // energy an agent pays for every claim it has the server check
+
+/*
+Messaging
+*/
+// the limits the server puts on the messages agents send each other. A limit of 0 means there is none
+type MessagingLimits struct {
+	Quota         int     // messages an agent can send in a round
+	MessageCost   float64 // energy an agent pays for every message it sends
+	RecipientCost float64 // energy an agent pays for every recipient of a message
+	MaxRecipients int     // recipients a message can have
+	Range         float64 // distance between the bikes of the sender and of a recipient beyond which a message isn't delivered
+}
+
+const MessageQuota int = 0 // default MessagingLimits
+const MessageCost float64 = 0.0
+const MessageRecipientCost float64 = 0.0
+const MaxMessageRecipients int = 0
+const MessageRange float64 = 0.0
+
+// when in a round the agents message each other
+type MessagingPhase int
+
+const (
+	BeforeLeaving       MessagingPhase = iota // before agents decide whether to leave their bike
+	BeforeDirectionVote                       // before the riders of each bike vote on its direction
+	BeforeAllocation                          // before the lootboxes reached are shared out
+	EndOfRound                                // once the round is over, also before the first round of a game
+	Campaign                                  // while the candidates in an election address the riders, before they vote
+)
+
+const InterleavedMessaging bool = false // if true agents also message each other before they take their decisions in the round
+
+// how unreliable the delivery of messages is
+type MessageChannel struct {
+	Delay    int     // number of messaging phases a message takes to arrive
+	DropRate float64 // probability that a delivery is lost
+}
+
+const MessageDelay int = 0 // default MessageChannel
+const MessageDropRate float64 = 0.0
+const MessagingSeed int64 = 2023 // seed of the random number generator of the drops, reset every game
+
+const InboxRounds int = 2 // agents keep the messages delivered to them in the last InboxRounds rounds
@@ -75,9 +75,31 @@ const (
 	Democracy Governance = iota
 	Leadership
 	Dictatorship
+	RotatingLeadership // the riders take turns at being the leader
+	Sortition          // the leader is drawn by lot among the riders
+	Council            // a council of elected riders votes on behalf of the bike
 	Invalid
 )
 
+func (g Governance) String() string {
+	switch g {
+	case Democracy:
+		return "democracy"
+	case Leadership:
+		return "leadership"
+	case Dictatorship:
+		return "dictatorship"
+	case RotatingLeadership:
+		return "rotating leadership"
+	case Sortition:
+		return "sortition"
+	case Council:
+		return "council"
+	default:
+		return "invalid"
+	}
+}
+
 type Action int
 
 const (
@@ -85,4 +107,67 @@ const (
 	Joining
 	Direction
 	Allocation
+	Election   // electing the ruler of a bike
+	RuleChange // changing the rules of a bike
 )
+
+func (m VoteMethod) String() string {
+	switch m {
+	case PLURALITY:
+		return "plurality"
+	case RUNOFF:
+		return "runoff"
+	case BORDACOUNT:
+		return "borda count"
+	case INSTANTRUNOFF:
+		return "instant runoff"
+	case APPROVAL:
+		return "approval"
+	case COPELANDSCORING:
+		return "copeland scoring"
+	case SCHULZE:
+		return "schulze"
+	case RANKEDPAIRS:
+		return "ranked pairs"
+	case KEMENYYOUNG:
+		return "kemeny-young"
+	case SCORE:
+		return "score"
+	case STAR:
+		return "star"
+	default:
+		return "unknown"
+	}
+}
+
+func (p WeightingPolicy) String() string {
+	switch p {
+	case EqualWeighting:
+		return "equal"
+	case ReputationWeighting:
+		return "reputation"
+	case SeniorityWeighting:
+		return "seniority"
+	case ContributionWeighting:
+		return "contribution"
+	default:
+		return "unknown"
+	}
+}
+
+func (p MessagingPhase) String() string {
+	switch p {
+	case BeforeLeaving:
+		return "before leaving"
+	case BeforeDirectionVote:
+		return "before direction vote"
+	case BeforeAllocation:
+		return "before allocation"
+	case EndOfRound:
+		return "end of round"
+	case Campaign:
+		return "campaign"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,54 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/utils"
+
+	"github.com/google/uuid"
+)
+
+// returns the shares of the loot given by the allocation method, the shares sum to one
+func AllocationFromDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64, method utils.AllocationMethod) (map[uuid.UUID]float64, error) {
+	switch method {
+	case utils.DHONDT:
+		return DHondtDist(voters, weights)
+	case utils.SAINTELAGUE:
+		return SainteLagueDist(voters, weights)
+	default:
+		return CumulativeDist(voters, weights)
+	}
+}
+
+// apportions utils.AllocationSeats parts of the loot with the D'Hondt method (divisors 1, 2, 3, ...),
+// which favours the riders with the most votes
+func DHondtDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64) (map[uuid.UUID]float64, error) {
+	return apportion(voters, weights, 1.0)
+}
+
+// apportions utils.AllocationSeats parts of the loot with the Sainte-Laguë method (divisors 1, 3, 5, ...),
+// which keeps the shares closest to the votes
+func SainteLagueDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64) (map[uuid.UUID]float64, error) {
+	return apportion(voters, weights, 2.0)
+}
+
+// gives every part of the loot in turn to the rider with the highest quotient of votes / (step * parts + 1)
+func apportion(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64, step float64) (map[uuid.UUID]float64, error) {
+	votes, err := CumulativeDist(voters, weights)
+	if err != nil {
+		return nil, err
+	}
+	order := newTieOrder(NewTieBreaker(utils.TieBreakRule, nil), sortedKeys(votes))
+	seats := make(map[uuid.UUID]int, len(votes))
+	for i := 0; i < utils.AllocationSeats; i++ {
+		quotients := make(map[uuid.UUID]float64, len(votes))
+		for id, vote := range votes {
+			quotients[id] = vote / (step*float64(seats[id]) + 1)
+		}
+		seats[order.best(quotients)]++
+	}
+
+	shares := make(map[uuid.UUID]float64, len(votes))
+	for id := range votes {
+		shares[id] = float64(seats[id]) / float64(utils.AllocationSeats)
+	}
+	return shares, nil
+}
@@ -0,0 +1,159 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// scores closer than this are treated as a tie
+const tieTolerance float64 = 1e-9
+
+// decides between candidates with equal scores. A nil TieBreaker picks the lowest UUID
+type TieBreaker struct {
+	Rule      utils.TieBreak
+	Incumbent uuid.UUID   // the candidate preferred by IncumbentTieBreak
+	Seniority []uuid.UUID // the candidates preferred by SeniorityTieBreak, most senior first
+	rng       *rand.Rand
+}
+
+// creates a tie breaker that draws from rng when it breaks ties at random.
+// If rng is nil, a generator seeded with utils.TieBreakSeed is used
+func NewTieBreaker(rule utils.TieBreak, rng *rand.Rand) *TieBreaker {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(utils.TieBreakSeed))
+	}
+	return &TieBreaker{Rule: rule, rng: rng}
+}
+
+// returns the candidates ordered from the most to the least preferred by the tie breaker
+func (tb *TieBreaker) Order(candidates []uuid.UUID) []uuid.UUID {
+	ordered := sortedIDs(candidates)
+	if tb == nil {
+		return ordered
+	}
+	switch tb.Rule {
+	case utils.RandomTieBreak:
+		if tb.rng == nil {
+			tb.rng = rand.New(rand.NewSource(utils.TieBreakSeed))
+		}
+		tb.rng.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	case utils.IncumbentTieBreak:
+		for i, candidate := range ordered {
+			if candidate == tb.Incumbent {
+				copy(ordered[1:i+1], ordered[:i])
+				ordered[0] = candidate
+				break
+			}
+		}
+	case utils.SeniorityTieBreak:
+		seniority := make(map[uuid.UUID]int, len(tb.Seniority))
+		for i, id := range tb.Seniority {
+			seniority[id] = i
+		}
+		rank := func(id uuid.UUID) int {
+			if i, ok := seniority[id]; ok {
+				return i
+			}
+			return len(tb.Seniority)
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return rank(ordered[i]) < rank(ordered[j])
+		})
+	}
+	return ordered
+}
+
+// returns the candidate the tie breaker prefers, or uuid.Nil if there are none
+func (tb *TieBreaker) Break(candidates []uuid.UUID) uuid.UUID {
+	if len(candidates) == 0 {
+		return uuid.Nil
+	}
+	return tb.Order(candidates)[0]
+}
+
+// returns a copy of the ids sorted from the lowest to the highest UUID
+func sortedIDs(ids []uuid.UUID) []uuid.UUID {
+	sorted := make([]uuid.UUID, len(ids))
+	copy(sorted, ids)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
+
+// returns the keys of the map sorted from the lowest to the highest UUID
+func sortedKeys[V any](m map[uuid.UUID]V) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return sortedIDs(keys)
+}
+
+// the position of every candidate in the order of the tie breaker
+type tieOrder map[uuid.UUID]int
+
+func newTieOrder(tieBreaker *TieBreaker, candidates []uuid.UUID) tieOrder {
+	order := make(tieOrder, len(candidates))
+	for i, candidate := range tieBreaker.Order(candidates) {
+		order[candidate] = i
+	}
+	return order
+}
+
+// ranks the candidates from the highest to the lowest score, equal scores are ranked by the tie order
+func (o tieOrder) rank(scores map[uuid.UUID]float64) []uuid.UUID {
+	ranked := sortedKeys(scores)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := scores[ranked[i]], scores[ranked[j]]
+		if math.Abs(a-b) > tieTolerance {
+			return a > b
+		}
+		return o[ranked[i]] < o[ranked[j]]
+	})
+	return ranked
+}
+
+// returns the candidate with the highest score, or uuid.Nil if there are none
+func (o tieOrder) best(scores map[uuid.UUID]float64) uuid.UUID {
+	ranked := o.rank(scores)
+	if len(ranked) == 0 {
+		return uuid.Nil
+	}
+	return ranked[0]
+}
+
+// returns the candidate the voter scores highest, ignoring candidates that are excluded or scored zero.
+// Returns uuid.Nil if the voter scores none of the remaining candidates
+func (o tieOrder) firstChoice(preference map[uuid.UUID]float64, excluded map[uuid.UUID]bool) uuid.UUID {
+	remaining := make(map[uuid.UUID]float64, len(preference))
+	for candidate, value := range preference {
+		if value > 0 && !excluded[candidate] {
+			remaining[candidate] = value
+		}
+	}
+	return o.best(remaining)
+}
+
+// weights every voter's ballot, returning the voters in ID order and every candidate on a ballot
+func weighBallots(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) ([]uuid.UUID, map[uuid.UUID]map[uuid.UUID]float64, []uuid.UUID) {
+	voters := sortedKeys(voteMap)
+	ballots := make(map[uuid.UUID]map[uuid.UUID]float64, len(voteMap))
+	candidateSet := make(map[uuid.UUID]bool)
+	for _, voter := range voters {
+		weight := voteWeight[voter]
+		weightedvotes := make(map[uuid.UUID]float64, len(voteMap[voter]))
+		for candidate, value := range voteMap[voter] {
+			weightedvotes[candidate] = value * weight
+			candidateSet[candidate] = true
+		}
+		ballots[voter] = weightedvotes
+	}
+	return voters, ballots, sortedKeys(candidateSet)
+}
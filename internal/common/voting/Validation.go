@@ -0,0 +1,98 @@
+package voting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// errors returned when a vote can't be counted
+var (
+	ErrNoVotes   = errors.New("no votes provided")
+	ErrZeroVotes = errors.New("all votes summed to zero")
+)
+
+// what is wrong with an entry of a ballot
+type BallotProblem int
+
+const (
+	NilCandidate     BallotProblem = iota // the ballot votes for uuid.Nil
+	UnknownCandidate                      // the ballot votes for someone or something that can't be chosen
+	InvalidValue                          // the ballot gives a candidate a negative, infinite or NaN vote
+)
+
+func (p BallotProblem) String() string {
+	switch p {
+	case NilCandidate:
+		return "voted for a nil uuid"
+	case UnknownCandidate:
+		return "voted for an unknown candidate"
+	case InvalidValue:
+		return "cast an invalid vote"
+	default:
+		return "cast an invalid ballot"
+	}
+}
+
+// reports the first invalid entry of a ballot. The invalid entries are removed before the ballot is counted
+type BallotError struct {
+	Voter     uuid.UUID
+	Candidate uuid.UUID
+	Value     float64
+	Problem   BallotProblem
+}
+
+func (e *BallotError) Error() string {
+	return fmt.Sprintf("agent %s %s (%s: %v)", e.Voter, e.Problem, e.Candidate, e.Value)
+}
+
+// returns a copy of the ballot without its invalid entries, and a *BallotError describing the first of them.
+// If isCandidate is nil, any candidate but uuid.Nil is valid
+func SanitiseBallot(voter uuid.UUID, votes map[uuid.UUID]float64, isCandidate func(uuid.UUID) bool) (IdVoteMap, error) {
+	var err *BallotError
+	sanitised := make(IdVoteMap, len(votes))
+	for _, candidate := range sortedKeys(votes) {
+		value := votes[candidate]
+		var problem BallotProblem
+		switch {
+		case candidate == uuid.Nil:
+			problem = NilCandidate
+		case isCandidate != nil && !isCandidate(candidate):
+			problem = UnknownCandidate
+		case value < 0 || math.IsNaN(value) || math.IsInf(value, 0):
+			problem = InvalidValue
+		default:
+			sanitised[candidate] = value
+			continue
+		}
+		if err == nil {
+			err = &BallotError{Voter: voter, Candidate: candidate, Value: value, Problem: problem}
+		}
+	}
+	if err != nil {
+		return sanitised, err
+	}
+	return sanitised, nil
+}
+
+// sanitises every ballot, returning the ballots that can be counted and an error for every ballot that had invalid entries.
+// Ballots that give no vote to any valid candidate are discarded
+func SanitiseBallots(voters map[uuid.UUID]IVoter, isCandidate func(uuid.UUID) bool) (map[uuid.UUID]IVoter, []error) {
+	ballots := make(map[uuid.UUID]IVoter, len(voters))
+	errs := make([]error, 0)
+	for _, voter := range sortedKeys(voters) {
+		if voters[voter] == nil {
+			continue
+		}
+		ballot, err := SanitiseBallot(voter, voters[voter].GetVotes(), isCandidate)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if SumOfValues(ballot) > 0 {
+			ballots[voter] = ballot
+		}
+	}
+	return ballots, errs
+}
@@ -3,6 +3,7 @@ package voting
 import (
 	"SOMAS2023/internal/common/utils"
 	"errors"
+	"math"
 	"sort"
 
 	"github.com/google/uuid"
@@ -77,11 +78,12 @@ func SumOfValues(voteMap IVoter) float64 {
 }
 
 // Returns the normalized vote outcome (assumes all the maps contain a voting between 0-1
-// for each option, and that all the votings sum to 1)
-func CumulativeDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64) map[uuid.UUID]float64 {
+// for each option, and that all the votings sum to 1). Invalid entries of the ballots are ignored
+func CumulativeDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64) (map[uuid.UUID]float64, error) {
 	if len(voters) == 0 {
-		panic("no votes provided")
+		return nil, ErrNoVotes
 	}
+	ballots, _ := SanitiseBallots(voters, nil)
 	// Vote checks for each voter
 	aggregateVotes := make(map[uuid.UUID]float64)
 
@@ -90,7 +92,8 @@ func CumulativeDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64)
 		aggregateVotes[voter] = 0.0
 	}
 
-	for agentID, voter := range voters {
+	for _, agentID := range sortedKeys(ballots) {
+		voter := ballots[agentID]
 		voteSum := SumOfValues(voter)
 		votes := voter.GetVotes()
 		weight := weights[agentID]
@@ -103,65 +106,85 @@ func CumulativeDist(voters map[uuid.UUID]IVoter, weights map[uuid.UUID]float64)
 	for _, vote := range aggregateVotes {
 		normalizeFactor += vote
 	}
-	if normalizeFactor == 0.0 {
-		panic("all votes summed to zero")
+	if normalizeFactor <= 0.0 || math.IsNaN(normalizeFactor) || math.IsInf(normalizeFactor, 0) {
+		return nil, ErrZeroVotes
 	}
 	// normalising step for all voters involved
 	for agentId, vote := range aggregateVotes {
 		aggregateVotes[agentId] = vote / normalizeFactor
 	}
-	return aggregateVotes
+	return aggregateVotes, nil
 }
 
-// return the votesMap
-func GetVotesMap(voters map[uuid.UUID]IVoter) map[uuid.UUID]map[uuid.UUID]float64 {
+// return the votesMap, with every ballot normalised. Invalid entries of the ballots are ignored
+func GetVotesMap(voters map[uuid.UUID]IVoter) (map[uuid.UUID]map[uuid.UUID]float64, error) {
 	if len(voters) == 0 {
-		panic("no votes provided")
+		return nil, ErrNoVotes
+	}
+	ballots, _ := SanitiseBallots(voters, nil)
+	if len(ballots) == 0 {
+		return nil, ErrZeroVotes
 	}
 	// Vote checks for each voter
 	VotesOfAgents := make(map[uuid.UUID]map[uuid.UUID]float64)
-	for agentID, voter := range voters {
+	for agentID, voter := range ballots {
 		voteSum := SumOfValues(voter)
 		votes := voter.GetVotes()
 		for id := range votes {
-			if id == uuid.Nil {
-				panic("agent voted for a nil uuid")
-			}
 			votes[id] /= voteSum
 		}
 		VotesOfAgents[agentID] = votes
 	}
 
-	return VotesOfAgents
+	return VotesOfAgents, nil
 }
 
 // returns the winner accoring to chosen voting strategy (assumes all the maps contain a voting between 0-1
 // for each option, and that all the votings sum to 1)
-func WinnerFromDist(voters map[uuid.UUID]IVoter, voteWeight map[uuid.UUID]float64) uuid.UUID {
-	VotesOfAgents := GetVotesMap(voters)
+func WinnerFromDist(voters map[uuid.UUID]IVoter, voteWeight map[uuid.UUID]float64) (uuid.UUID, error) {
+	return WinnerFromDistWithMethod(voters, voteWeight, utils.VoteAction, NewTieBreaker(utils.TieBreakRule, nil))
+}
+
+// returns the winner of a vote using the given voting method, ties are broken by the tie breaker.
+// Returns an error if no ballot can be counted
+func WinnerFromDistWithMethod(voters map[uuid.UUID]IVoter, voteWeight map[uuid.UUID]float64, method utils.VoteMethod, tieBreaker *TieBreaker) (uuid.UUID, error) {
+	VotesOfAgents, err := GetVotesMap(voters)
+	if err != nil {
+		return uuid.Nil, err
+	}
 	var winner uuid.UUID
-	switch utils.VoteAction {
+	switch method {
 	case utils.PLURALITY:
-		winner = Plurality(VotesOfAgents, voteWeight)
+		winner = Plurality(VotesOfAgents, voteWeight, tieBreaker)
 	case utils.RUNOFF:
-		winner = Runoff(VotesOfAgents, voteWeight)
+		winner = Runoff(VotesOfAgents, voteWeight, tieBreaker)
 	case utils.BORDACOUNT:
-		winner = BordaCount(VotesOfAgents, voteWeight)
+		winner = BordaCount(VotesOfAgents, voteWeight, tieBreaker)
 	case utils.INSTANTRUNOFF:
-		winner = InstantRunoff(VotesOfAgents, voteWeight)
+		winner = InstantRunoff(VotesOfAgents, voteWeight, tieBreaker)
 	case utils.APPROVAL:
-		winner = Approval(VotesOfAgents, voteWeight)
+		winner = Approval(VotesOfAgents, voteWeight, tieBreaker)
 	case utils.COPELANDSCORING:
-		winner = CopelandScoring(VotesOfAgents, voteWeight)
+		winner = CopelandScoring(VotesOfAgents, voteWeight, tieBreaker)
+	case utils.SCHULZE:
+		winner = Schulze(VotesOfAgents, voteWeight, tieBreaker)
+	case utils.RANKEDPAIRS:
+		winner = RankedPairs(VotesOfAgents, voteWeight, tieBreaker)
+	case utils.KEMENYYOUNG:
+		winner = KemenyYoung(VotesOfAgents, voteWeight, tieBreaker)
+	case utils.SCORE:
+		winner = Score(VotesOfAgents, voteWeight, tieBreaker)
+	case utils.STAR:
+		winner = STAR(VotesOfAgents, voteWeight, tieBreaker)
 	}
 	// TODO call group 8 voting function
-	return winner
+	return winner, nil
 }
 
 func WinnerFromGovernance(voters []GovernanceVote) (utils.Governance, error) {
 	// check if length of votes is greater than one
 	if len(voters) == 0 {
-		return utils.Invalid, errors.New("no votes provided")
+		return utils.Invalid, ErrNoVotes
 	}
 
 	// Summing up the votes for each governance type
@@ -170,7 +193,7 @@ func WinnerFromGovernance(voters []GovernanceVote) (utils.Governance, error) {
 		for _, votes := range vote {
 			sum += votes
 		}
-		if sum > 1.0 {
+		if sum > 1.0+utils.Epsilon {
 			return utils.Invalid, errors.New("distribution doesn't sum to 1")
 		}
 	}
@@ -200,7 +223,7 @@ func WinnerFromGovernance(voters []GovernanceVote) (utils.Governance, error) {
 func TallyFoundingVotes(voters map[uuid.UUID]utils.Governance) (map[utils.Governance]int, error) {
 	// check if length of votes is greater than one
 	if len(voters) == 0 {
-		return nil, errors.New("no votes provided")
+		return nil, ErrNoVotes
 	}
 
 	// Summing up the votes for each governance type
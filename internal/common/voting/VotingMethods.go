@@ -1,6 +1,7 @@
 package voting
 
 import (
+	"SOMAS2023/internal/common/utils"
 	"math"
 	"sort"
 
@@ -13,54 +14,32 @@ type kv struct {
 	Value float64
 }
 
-func Plurality(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func Plurality(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		Plurality:
 			Each voter selects one candidate and the candidate with the most first-placed votes is the winner.
 	*/
 
 	//initialise the votes with weights
-	var voteList []map[uuid.UUID]float64
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteList = append(voteList, weightedvotes)
-	}
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	voteCount := make(map[uuid.UUID]float64)
-	var winner uuid.UUID
 
-	for _, preference := range voteList {
-		var maxPreference float64
-		var firstLootBoxChoice uuid.UUID
-		for lootBox, value := range preference {
-			if value > maxPreference {
-				firstLootBoxChoice = lootBox
-				maxPreference = value
-			}
+	for _, voter := range voters {
+		preference := voteList[voter]
+		firstLootBoxChoice := order.firstChoice(preference, nil)
+		if firstLootBoxChoice != uuid.Nil {
+			voteCount[firstLootBoxChoice] += preference[firstLootBoxChoice]
 		}
-		voteCount[firstLootBoxChoice] += maxPreference
 	}
 
 	// final step: we need to find the winner with highest count number in map.
-	var maxVotes float64
-
-	for lootBox, votes := range voteCount {
-		if votes > maxVotes {
-			maxVotes = votes
-			winner = lootBox
-		}
-	}
-
-	// return the final winner
-	return winner
+	return order.best(voteCount)
 }
 
-func Runoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func Runoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		Runoff:
 			1st round: 	each voter selects one candidate, and the two candidates with most first-placed votes are identified.
@@ -68,121 +47,96 @@ func Runoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUI
 			2nd round: 	each voter selects one candidate, the candidate with most votes now is the winner.
 	*/
 	//initialise the votes with weights
-	var voteList []map[uuid.UUID]float64
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteList = append(voteList, weightedvotes)
-	}
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	voteCount := make(map[uuid.UUID]float64)
-	var winner uuid.UUID
 
 	// ----- first round -----
 	// find the count number of each lootbox
-	for _, preference := range voteList {
-		var maxPreference float64
-		var firstLootBoxChoice uuid.UUID
-		for lootBox, value := range preference {
-			if value > maxPreference {
-				firstLootBoxChoice = lootBox
-				maxPreference = value
-			}
+	for _, voter := range voters {
+		preference := voteList[voter]
+		firstLootBoxChoice := order.firstChoice(preference, nil)
+		if firstLootBoxChoice != uuid.Nil {
+			voteCount[firstLootBoxChoice] += preference[firstLootBoxChoice]
 		}
-		voteCount[firstLootBoxChoice] += maxPreference
 	}
 
 	// find the two candidates with most first-placed votes
-	var maxVotes1, maxVotes2 float64
-	var winner1, winner2 uuid.UUID
-	for lootBox, votes := range voteCount {
-		if votes > maxVotes1 {
-			winner2 = winner1
-			maxVotes2 = maxVotes1
-			winner1 = lootBox
-			maxVotes1 = votes
-		} else if votes > maxVotes2 {
-			winner2 = lootBox
-			maxVotes2 = votes
-		}
+	ranked := order.rank(voteCount)
+	if len(ranked) < 2 {
+		return order.best(voteCount)
 	}
+	winner1, winner2 := ranked[0], ranked[1]
 
 	// check if either already has a majority or we need the second round
-	if maxVotes1 >= (maxVotes2 * 2) {
+	if voteCount[winner1] >= (voteCount[winner2] * 2) {
 		// return the majority lootbox
 		return winner1
-	} else {
-		// ----- second round -----
-		voteCount := make(map[uuid.UUID]float64)
-		for _, preference := range voteList {
-			if preference[winner1] > preference[winner2] {
-				voteCount[winner1] += preference[winner1]
-			} else {
-				voteCount[winner2] += preference[winner2]
-			}
-		}
-		if voteCount[winner1] > voteCount[winner2] {
-			winner = winner1
+	}
+
+	// ----- second round -----
+	// a voter who likes both candidates equally votes for the one the tie breaker prefers
+	preferred, other := winner1, winner2
+	if order[winner2] < order[winner1] {
+		preferred, other = winner2, winner1
+	}
+	voteCount = map[uuid.UUID]float64{winner1: 0, winner2: 0}
+	for _, voter := range voters {
+		preference := voteList[voter]
+		if preference[other] > preference[preferred] {
+			voteCount[other] += preference[other]
 		} else {
-			winner = winner2
+			voteCount[preferred] += preference[preferred]
 		}
 	}
 
-	return winner
+	return order.best(voteCount)
 }
 
-func BordaCount(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func BordaCount(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		BordaCount:
 			Each voter rank order all the candidates. With n candidates being ranked k scores (n-k)+1 Borda points.
 			The candidate with the highest Borda Score is the winner
 	*/
 	//initialise the votes with weights
-	voteListMap := make(map[uuid.UUID]map[uuid.UUID]float64)
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteListMap[agent] = weightedvotes
-	}
+	voters, voteListMap, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	voteCount := make(map[uuid.UUID]float64)
-	var winner uuid.UUID
 
 	// initialise the map with all candidates
-	for _, preference := range voteListMap {
-		for key := range preference {
-			voteCount[key] = 0
-		}
+	for _, candidate := range candidates {
+		voteCount[candidate] = 0
 	}
 
 	// covert the unodered map into ordered list
 	ss := make(map[uuid.UUID][]kv)
-	for agent, preference := range voteListMap {
+	for _, agent := range voters {
 		var s []kv
-		for k, v := range preference {
+		for _, k := range candidates {
 			// ignore the lootbox if value is 0
-			if v != 0 {
+			if v := voteListMap[agent][k]; v != 0 {
 				s = append(s, kv{k, v})
 			}
 		}
 		// sort the list using preference value of each lootbox
-		sort.Slice(s, func(i, j int) bool {
-			// in the order from large to small
-			return s[i].Value > s[j].Value
+		sort.SliceStable(s, func(i, j int) bool {
+			// in the order from large to small, equal preferences are ordered by the tie breaker
+			if s[i].Value != s[j].Value {
+				return s[i].Value > s[j].Value
+			}
+			return order[s[i].Key] < order[s[j].Key]
 		})
 		ss[agent] = s
 	}
 
 	// calculate the Borda score for each candidates
-	for agent, sortedList := range ss {
+	for _, agent := range voters {
+		sortedList := ss[agent]
 		usedKeys := make(map[uuid.UUID]bool)
 		for i, kv := range sortedList {
 			score := float64(len(voteCount)) - float64(i) + 1
@@ -201,44 +155,26 @@ func BordaCount(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid
 	}
 
 	// find the winner with highest score
-	var maxScore float64
-	for key, value := range voteCount {
-		if value > maxScore {
-			winner = key
-			maxScore = value
-		}
-	}
-
-	return winner
+	return order.best(voteCount)
 }
 
-func InstantRunoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func InstantRunoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		InstantRunoff:
 			Each voter rank orders all candidates, and the candidate with the least number of first-place votes is eliminate.
 			This is repeated until only one candidate remains
 	*/
 	//initialise the votes with weights
-	var voteList []map[uuid.UUID]float64
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteList = append(voteList, weightedvotes)
-	}
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	voteCount := make(map[uuid.UUID]float64)
 	eliminateVote := make(map[uuid.UUID]bool)
-	var winner uuid.UUID
 
 	// initialise the map with all candidates
-	for _, preference := range voteList {
-		for key := range preference {
-			voteCount[key] = 0
-		}
+	for _, candidate := range candidates {
+		voteCount[candidate] = 0
 	}
 
 	// loop to eliminate the least number of first-place votes
@@ -249,62 +185,40 @@ func InstantRunoff(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[u
 		}
 
 		// count the number of first-place votes for each lootbox
-		for _, preference := range voteList {
-			var maxScore float64
-			var firstLootBoxChoice uuid.UUID
-			for key, value := range preference {
-				if (value > maxScore) && !eliminateVote[key] {
-					maxScore = value
-					firstLootBoxChoice = key
-				}
+		for _, voter := range voters {
+			preference := voteList[voter]
+			firstLootBoxChoice := order.firstChoice(preference, eliminateVote)
+			if firstLootBoxChoice != uuid.Nil {
+				voteCount[firstLootBoxChoice] += preference[firstLootBoxChoice]
 			}
-			voteCount[firstLootBoxChoice] += maxScore
 		}
 
-		// eliminate the lootbox with least votes
-		var minVotes float64 = math.MaxFloat64
-		var candidateToEliminate uuid.UUID
-		for key, value := range voteCount {
-			if value < minVotes {
-				minVotes = value
-				candidateToEliminate = key
-			}
-		}
+		// eliminate the lootbox with least votes, of those the one the tie breaker likes least
+		ranked := order.rank(voteCount)
+		candidateToEliminate := ranked[len(ranked)-1]
 		eliminateVote[candidateToEliminate] = true
 		delete(voteCount, candidateToEliminate)
 	}
 
 	// get the final winner
-	for key := range voteCount {
-		winner = key
-	}
-
-	return winner
+	return order.best(voteCount)
 }
 
-func Approval(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func Approval(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		Approval:
 			A ballot represents not a linear rank order of decreasing preference,
 			but rather represents the set of candidates who are 'equally acceptable' to the voter
 	*/
 	//initialise the votes with weights
-	var voteList []map[uuid.UUID]float64
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteList = append(voteList, weightedvotes)
-	}
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	voteCount := make(map[uuid.UUID]float64)
-	var winner uuid.UUID
 
-	for _, preference := range voteList {
-		for key, value := range preference {
+	for _, voter := range voters {
+		for key, value := range voteList[voter] {
 			if value > 0 {
 				voteCount[key] += value
 			}
@@ -312,49 +226,39 @@ func Approval(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.U
 	}
 
 	// find the lootbox with max score
-	var maxVotes float64
-
-	for lootBox, votes := range voteCount {
-		if votes > maxVotes {
-			maxVotes = votes
-			winner = lootBox
-		}
-	}
-
-	return winner
+	return order.best(voteCount)
 }
 
-func CopelandScoring(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64) uuid.UUID {
+func CopelandScoring(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
 	/*
 		CopelandScoring:
 			Each voter submits a ballot with a linear rank order.
 			A win-loss record, the Copeland Score, is calculated for each candidate.
 	*/
 	//initialise the votes with weights
-	voteListMap := make(map[uuid.UUID]map[uuid.UUID]float64)
-	for agent, votes := range voteMap {
-		weight := voteWeight[agent]
-		weightedvotes := make(map[uuid.UUID]float64)
-		for key, value := range votes {
-			weightedvotes[key] = value * weight
-		}
-		voteListMap[agent] = weightedvotes
-	}
+	voters, voteListMap, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
 
 	// start
 	// the map to store the winning score for each lootbox
 	scores := make(map[uuid.UUID]float64)
+	for _, candidate := range candidates {
+		scores[candidate] = 0
+	}
 
 	// iterate the voting
-	for agent, vote := range voteListMap {
-		for candidate1, score1 := range vote {
-			for candidate2, score2 := range vote {
+	for _, agent := range voters {
+		vote := voteListMap[agent]
+		ranked := sortedKeys(vote)
+		for _, candidate1 := range ranked {
+			for _, candidate2 := range ranked {
 				// do not compare with itself
 				if candidate1 == candidate2 {
 					continue
 				}
 
 				// update the score of each lootbox
+				score1, score2 := vote[candidate1], vote[candidate2]
 				if score1 > score2 {
 					scores[candidate1] += voteWeight[agent]
 					scores[candidate2] -= voteWeight[agent]
@@ -367,14 +271,275 @@ func CopelandScoring(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map
 	}
 
 	// find the lootbox with the highest score
-	var maxScore float64
-	var maxCandidate uuid.UUID
-	for candidate, score := range scores {
-		if score > maxScore || maxCandidate == uuid.Nil {
-			maxScore = score
-			maxCandidate = candidate
+	return order.best(scores)
+}
+
+// pairwise preferences of the voters: the weight of the voters that score the first candidate above the second
+func pairwisePreferences(voters []uuid.UUID, voteList map[uuid.UUID]map[uuid.UUID]float64, candidates []uuid.UUID, voteWeight map[uuid.UUID]float64) map[uuid.UUID]map[uuid.UUID]float64 {
+	preferences := make(map[uuid.UUID]map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		preferences[candidate] = make(map[uuid.UUID]float64, len(candidates))
+	}
+	for _, voter := range voters {
+		vote := voteList[voter]
+		for _, candidate1 := range candidates {
+			for _, candidate2 := range candidates {
+				if vote[candidate1] > vote[candidate2] {
+					preferences[candidate1][candidate2] += voteWeight[voter]
+				}
+			}
+		}
+	}
+	return preferences
+}
+
+// converts every ballot into scores between 0 and utils.ScoreVotingMax, the favourite candidates of a voter get the highest score
+func rangeScores(voters []uuid.UUID, voteList map[uuid.UUID]map[uuid.UUID]float64) map[uuid.UUID]map[uuid.UUID]float64 {
+	scores := make(map[uuid.UUID]map[uuid.UUID]float64, len(voters))
+	for _, voter := range voters {
+		var maxPreference float64
+		for _, value := range voteList[voter] {
+			maxPreference = math.Max(maxPreference, value)
+		}
+		scores[voter] = make(map[uuid.UUID]float64, len(voteList[voter]))
+		if maxPreference <= 0 {
+			continue
+		}
+		for candidate, value := range voteList[voter] {
+			if value > 0 {
+				scores[voter][candidate] = math.Round(value / maxPreference * float64(utils.ScoreVotingMax))
+			}
+		}
+	}
+	return scores
+}
+
+func Schulze(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
+	/*
+		Schulze:
+			Each voter submits a ballot with a linear rank order. The strength of a path between two candidates
+			is its weakest pairwise win, and the winner beats or ties every other candidate on its strongest paths.
+	*/
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
+	preferences := pairwisePreferences(voters, voteList, candidates, voteWeight)
+
+	// the strongest paths start with the pairwise wins
+	paths := make(map[uuid.UUID]map[uuid.UUID]float64, len(candidates))
+	for _, candidate1 := range candidates {
+		paths[candidate1] = make(map[uuid.UUID]float64, len(candidates))
+		for _, candidate2 := range candidates {
+			if preferences[candidate1][candidate2] > preferences[candidate2][candidate1] {
+				paths[candidate1][candidate2] = preferences[candidate1][candidate2]
+			}
+		}
+	}
+	for _, via := range candidates {
+		for _, from := range candidates {
+			if from == via {
+				continue
+			}
+			for _, to := range candidates {
+				if to == via || to == from {
+					continue
+				}
+				paths[from][to] = math.Max(paths[from][to], math.Min(paths[from][via], paths[via][to]))
+			}
+		}
+	}
+
+	// the winners are not beaten by any other candidate
+	wins := make(map[uuid.UUID]float64, len(candidates))
+	for _, candidate1 := range candidates {
+		for _, candidate2 := range candidates {
+			if candidate1 != candidate2 && paths[candidate1][candidate2] >= paths[candidate2][candidate1] {
+				wins[candidate1]++
+			}
+		}
+	}
+
+	return order.best(wins)
+}
+
+func RankedPairs(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
+	/*
+		RankedPairs:
+			Each voter submits a ballot with a linear rank order. The pairwise wins are locked in from the largest
+			margin to the smallest, skipping any that would create a cycle. The winner is beaten in no locked pair.
+	*/
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
+	preferences := pairwisePreferences(voters, voteList, candidates, voteWeight)
+
+	type pair struct {
+		winner, loser uuid.UUID
+		margin        float64
+	}
+	var pairs []pair
+	for _, candidate1 := range candidates {
+		for _, candidate2 := range candidates {
+			if margin := preferences[candidate1][candidate2] - preferences[candidate2][candidate1]; margin > 0 {
+				pairs = append(pairs, pair{candidate1, candidate2, margin})
+			}
+		}
+	}
+	// equal margins are locked in the order the tie breaker prefers their winners, then their losers
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if math.Abs(pairs[i].margin-pairs[j].margin) > tieTolerance {
+			return pairs[i].margin > pairs[j].margin
+		}
+		if pairs[i].winner != pairs[j].winner {
+			return order[pairs[i].winner] < order[pairs[j].winner]
+		}
+		return order[pairs[i].loser] < order[pairs[j].loser]
+	})
+
+	locked := make(map[uuid.UUID][]uuid.UUID, len(candidates))
+	var reaches func(from, to uuid.UUID) bool
+	reaches = func(from, to uuid.UUID) bool {
+		if from == to {
+			return true
+		}
+		for _, next := range locked[from] {
+			if reaches(next, to) {
+				return true
+			}
+		}
+		return false
+	}
+	beaten := make(map[uuid.UUID]bool, len(candidates))
+	for _, p := range pairs {
+		if !reaches(p.loser, p.winner) {
+			locked[p.winner] = append(locked[p.winner], p.loser)
+			beaten[p.loser] = true
+		}
+	}
+
+	sources := make(map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		if !beaten[candidate] {
+			sources[candidate] = 1
+		}
+	}
+
+	return order.best(sources)
+}
+
+func KemenyYoung(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
+	/*
+		KemenyYoung:
+			Each voter submits a ballot with a linear rank order. Every ranking of the candidates scores the pairwise
+			preferences it agrees with, and the first candidate of the highest scoring ranking is the winner.
+			This is only feasible for a few candidates, with more than utils.KemenyYoungMaxCandidates Schulze is used.
+	*/
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	if len(candidates) > utils.KemenyYoungMaxCandidates {
+		return Schulze(voteMap, voteWeight, tieBreaker)
+	}
+	if len(candidates) == 0 {
+		return uuid.Nil
+	}
+	ordered := tieBreaker.Order(candidates)
+	preferences := pairwisePreferences(voters, voteList, candidates, voteWeight)
+
+	// try every ranking, starting from the order of the tie breaker so that it wins ties
+	var winner uuid.UUID
+	bestScore := math.Inf(-1)
+	ranking := make([]uuid.UUID, 0, len(ordered))
+	used := make(map[uuid.UUID]bool, len(ordered))
+	var permute func(score float64)
+	permute = func(score float64) {
+		if len(ranking) == len(ordered) {
+			if score > bestScore+tieTolerance {
+				bestScore = score
+				winner = ranking[0]
+			}
+			return
+		}
+		for _, candidate := range ordered {
+			if used[candidate] {
+				continue
+			}
+			// the candidate is ranked below everyone already placed
+			added := 0.0
+			for _, above := range ranking {
+				added += preferences[above][candidate]
+			}
+			used[candidate] = true
+			ranking = append(ranking, candidate)
+			permute(score + added)
+			ranking = ranking[:len(ranking)-1]
+			used[candidate] = false
+		}
+	}
+	permute(0)
+
+	return winner
+}
+
+func Score(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
+	/*
+		Score:
+			Each voter scores every candidate between 0 and utils.ScoreVotingMax, with their favourite getting the top score.
+			The candidate with the highest total score is the winner.
+	*/
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
+
+	totals := make(map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		totals[candidate] = 0
+	}
+	scores := rangeScores(voters, voteList)
+	for _, voter := range voters {
+		for candidate, score := range scores[voter] {
+			totals[candidate] += score * voteWeight[voter]
+		}
+	}
+
+	return order.best(totals)
+}
+
+func STAR(voteMap map[uuid.UUID]map[uuid.UUID]float64, voteWeight map[uuid.UUID]float64, tieBreaker *TieBreaker) uuid.UUID {
+	/*
+		STAR (Score Then Automatic Runoff):
+			Each voter scores the candidates as in score voting and the two candidates with the highest total score
+			go to a runoff. Each voter supports the finalist they scored higher, and the finalist with most support wins.
+			A tied runoff goes to the finalist with the higher total score.
+	*/
+	voters, voteList, candidates := weighBallots(voteMap, voteWeight)
+	order := newTieOrder(tieBreaker, candidates)
+
+	// ----- scoring round -----
+	totals := make(map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		totals[candidate] = 0
+	}
+	scores := rangeScores(voters, voteList)
+	for _, voter := range voters {
+		for candidate, score := range scores[voter] {
+			totals[candidate] += score * voteWeight[voter]
 		}
 	}
+	ranked := order.rank(totals)
+	if len(ranked) < 2 {
+		return order.best(totals)
+	}
+	finalist1, finalist2 := ranked[0], ranked[1]
+
+	// ----- automatic runoff -----
+	support := map[uuid.UUID]float64{finalist1: 0, finalist2: 0}
+	for _, voter := range voters {
+		if scores[voter][finalist1] > scores[voter][finalist2] {
+			support[finalist1] += voteWeight[voter]
+		} else if scores[voter][finalist1] < scores[voter][finalist2] {
+			support[finalist2] += voteWeight[voter]
+		}
+	}
+	if math.Abs(support[finalist1]-support[finalist2]) <= tieTolerance {
+		// finalist1 has the higher score, or is preferred by the tie breaker
+		return finalist1
+	}
 
-	return maxCandidate
+	return order.best(support)
 }
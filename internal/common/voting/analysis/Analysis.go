@@ -0,0 +1,241 @@
+package analysis
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// decides the winner of a vote, e.g. voting.WinnerFromDistWithMethod with a fixed voting method and tie breaker
+type Method func(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64) (uuid.UUID, error)
+
+// what the ballots of a vote reveal about its outcome
+type Report struct {
+	Winner   uuid.UUID `json:"winner"`
+	RunnerUp uuid.UUID `json:"runner_up"` // the candidate closest to beating the winner head to head
+	// the share of the vote weight by which the winner beats the runner up head to head, negative if the runner up wins
+	Margin          float64   `json:"margin"`
+	CondorcetWinner uuid.UUID `json:"condorcet_winner"` // uuid.Nil if no candidate beats every other one head to head
+	// the voters that could have elected a candidate they prefer to the winner by misreporting on their own
+	Manipulators []uuid.UUID `json:"manipulators"`
+	// the candidates that the voters preferring them to the winner could have elected by voting together
+	CoalitionCandidates []uuid.UUID `json:"coalition_candidates"`
+}
+
+// reports whether the outcome of the vote could have been changed by strategic voting, the margin of victory
+// and the Condorcet winner. The ballots are taken as the sincere preferences of the voters
+func Analyse(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, method Method) (Report, error) {
+	ballots, _ := voting.SanitiseBallots(voters, nil)
+	winner, err := method(ballots, weights)
+	if err != nil {
+		return Report{}, err
+	}
+	return AnalyseOutcome(ballots, weights, winner, method), nil
+}
+
+// like Analyse, for a vote whose winner is already known, e.g. when the method breaks ties at random
+// and running it again could elect someone else
+func AnalyseOutcome(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, winner uuid.UUID, method Method) Report {
+	ballots, _ := voting.SanitiseBallots(voters, nil)
+	voterIDs := sortedIDs(ballots)
+	candidateSet := make(map[uuid.UUID]bool)
+	if winner != uuid.Nil {
+		candidateSet[winner] = true
+	}
+	for _, ballot := range ballots {
+		for candidate := range ballot.GetVotes() {
+			candidateSet[candidate] = true
+		}
+	}
+	candidates := sortedIDs(candidateSet)
+
+	report := Report{
+		Winner:              winner,
+		Manipulators:        make([]uuid.UUID, 0),
+		CoalitionCandidates: make([]uuid.UUID, 0),
+	}
+	report.RunnerUp, report.Margin = marginOfVictory(ballots, weights, voterIDs, candidates, winner)
+	report.CondorcetWinner = condorcetWinner(ballots, weights, voterIDs, candidates)
+
+	// single voters
+	for _, voter := range voterIDs {
+		sincere := ballots[voter].GetVotes()
+		for _, misreport := range misreports(sincere, winner, candidates) {
+			trial := replaceBallots(ballots, map[uuid.UUID]voting.IVoter{voter: misreport})
+			if result, err := method(trial, weights); err == nil && sincere[result] > sincere[winner] {
+				report.Manipulators = append(report.Manipulators, voter)
+				break
+			}
+		}
+	}
+
+	// coalitions of the voters that prefer a candidate to the winner
+	for _, candidate := range candidates {
+		if candidate == winner {
+			continue
+		}
+		coalition := make([]uuid.UUID, 0)
+		for _, voter := range voterIDs {
+			if votes := ballots[voter].GetVotes(); votes[candidate] > votes[winner] {
+				coalition = append(coalition, voter)
+			}
+		}
+		if len(coalition) == 0 {
+			continue
+		}
+		bullets := make(map[uuid.UUID]voting.IVoter, len(coalition))
+		compromises := make(map[uuid.UUID]voting.IVoter, len(coalition))
+		for _, voter := range coalition {
+			bullets[voter] = voting.IdVoteMap{candidate: 1.0}
+			compromises[voter] = rankedBallot(compromise(ballots[voter].GetVotes(), candidate, winner, candidates))
+		}
+		for _, misreport := range []map[uuid.UUID]voting.IVoter{bullets, compromises} {
+			if result, err := method(replaceBallots(ballots, misreport), weights); err == nil && result == candidate {
+				report.CoalitionCandidates = append(report.CoalitionCandidates, candidate)
+				break
+			}
+		}
+	}
+
+	return report
+}
+
+// the weight of the voters that score the first candidate above the second
+func pairwisePreferences(ballots map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, voterIDs []uuid.UUID, candidates []uuid.UUID) map[uuid.UUID]map[uuid.UUID]float64 {
+	preferences := make(map[uuid.UUID]map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		preferences[candidate] = make(map[uuid.UUID]float64, len(candidates))
+	}
+	for _, voter := range voterIDs {
+		votes := ballots[voter].GetVotes()
+		for _, candidate1 := range candidates {
+			for _, candidate2 := range candidates {
+				if votes[candidate1] > votes[candidate2] {
+					preferences[candidate1][candidate2] += weights[voter]
+				}
+			}
+		}
+	}
+	return preferences
+}
+
+// returns the candidate that comes closest to beating the winner head to head and the winner's margin over them
+func marginOfVictory(ballots map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, voterIDs []uuid.UUID, candidates []uuid.UUID, winner uuid.UUID) (uuid.UUID, float64) {
+	totalWeight := 0.0
+	for _, voter := range voterIDs {
+		totalWeight += weights[voter]
+	}
+	if totalWeight <= 0 {
+		return uuid.Nil, 0.0
+	}
+	preferences := pairwisePreferences(ballots, weights, voterIDs, candidates)
+	runnerUp, margin := uuid.Nil, 1.0
+	for _, candidate := range candidates {
+		if candidate == winner {
+			continue
+		}
+		if m := (preferences[winner][candidate] - preferences[candidate][winner]) / totalWeight; runnerUp == uuid.Nil || m < margin {
+			runnerUp, margin = candidate, m
+		}
+	}
+	return runnerUp, margin
+}
+
+func condorcetWinner(ballots map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, voterIDs []uuid.UUID, candidates []uuid.UUID) uuid.UUID {
+	preferences := pairwisePreferences(ballots, weights, voterIDs, candidates)
+outer:
+	for _, candidate1 := range candidates {
+		for _, candidate2 := range candidates {
+			if candidate1 != candidate2 && preferences[candidate1][candidate2] <= preferences[candidate2][candidate1] {
+				continue outer
+			}
+		}
+		return candidate1
+	}
+	return uuid.Nil
+}
+
+// the ballots a voter could cast instead of their sincere one to elect a candidate they prefer to the winner
+func misreports(sincere map[uuid.UUID]float64, winner uuid.UUID, candidates []uuid.UUID) []voting.IVoter {
+	ballots := make([]voting.IVoter, 0)
+	for _, candidate := range candidates {
+		if sincere[candidate] > sincere[winner] {
+			ballots = append(ballots, voting.IdVoteMap{candidate: 1.0})
+			ballots = append(ballots, rankedBallot(compromise(sincere, candidate, winner, candidates)))
+		}
+	}
+	if len(ballots) == 0 || len(candidates) > utils.AnalysisMaxCandidates {
+		return ballots
+	}
+	// with few candidates every ranking is tried
+	var permute func(ranking []uuid.UUID, remaining []uuid.UUID)
+	permute = func(ranking []uuid.UUID, remaining []uuid.UUID) {
+		if len(remaining) == 0 {
+			ballots = append(ballots, rankedBallot(ranking))
+			return
+		}
+		for i, candidate := range remaining {
+			rest := append(append(make([]uuid.UUID, 0, len(remaining)-1), remaining[:i]...), remaining[i+1:]...)
+			permute(append(ranking, candidate), rest)
+		}
+	}
+	permute(make([]uuid.UUID, 0, len(candidates)), candidates)
+	return ballots
+}
+
+// ranks the favourite first and the winner last, the other candidates keep their sincere order
+func compromise(sincere map[uuid.UUID]float64, favourite uuid.UUID, winner uuid.UUID, candidates []uuid.UUID) []uuid.UUID {
+	ranking := make([]uuid.UUID, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != favourite && candidate != winner {
+			ranking = append(ranking, candidate)
+		}
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return sincere[ranking[i]] > sincere[ranking[j]]
+	})
+	ranking = append([]uuid.UUID{favourite}, ranking...)
+	if winner != favourite && winner != uuid.Nil {
+		ranking = append(ranking, winner)
+	}
+	return ranking
+}
+
+// a ballot that scores the candidates in the order of the ranking, the last one gets nothing
+func rankedBallot(ranking []uuid.UUID) voting.IdVoteMap {
+	ballot := make(voting.IdVoteMap, len(ranking))
+	if len(ranking) == 1 {
+		ballot[ranking[0]] = 1.0
+		return ballot
+	}
+	total := float64(len(ranking)*(len(ranking)-1)) / 2
+	for i, candidate := range ranking {
+		ballot[candidate] = float64(len(ranking)-1-i) / total
+	}
+	return ballot
+}
+
+// returns a copy of the ballots with some of them replaced
+func replaceBallots(ballots map[uuid.UUID]voting.IVoter, replacements map[uuid.UUID]voting.IVoter) map[uuid.UUID]voting.IVoter {
+	replaced := make(map[uuid.UUID]voting.IVoter, len(ballots))
+	for voter, ballot := range ballots {
+		replaced[voter] = ballot
+	}
+	for voter, ballot := range replacements {
+		replaced[voter] = ballot
+	}
+	return replaced
+}
+
+func sortedIDs[V any](m map[uuid.UUID]V) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
@@ -0,0 +1,90 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/common/voting/analysis"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func pluralityWinner(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64) (uuid.UUID, error) {
+	return voting.WinnerFromDistWithMethod(voters, weights, utils.PLURALITY, nil)
+}
+
+func TestAnalysisFindsManipulators(t *testing.T) {
+	a := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	b := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	c := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	voterIDs := newIDs(5)
+	voters := map[uuid.UUID]voting.IVoter{
+		voterIDs[0]: voting.IdVoteMap{a: 1.0},
+		voterIDs[1]: voting.IdVoteMap{a: 1.0},
+		voterIDs[2]: voting.IdVoteMap{b: 1.0},
+		voterIDs[3]: voting.IdVoteMap{b: 1.0},
+		// prefers c, then b, then a
+		voterIDs[4]: voting.IdVoteMap{c: 0.6, b: 0.4},
+	}
+	weights := make(map[uuid.UUID]float64)
+	for _, voter := range voterIDs {
+		weights[voter] = 1.0
+	}
+
+	report, err := analysis.Analyse(voters, weights, pluralityWinner)
+
+	assert.NoError(t, err)
+	// a wins the tie with b by having the lowest UUID
+	assert.Equal(t, a, report.Winner)
+	// but b beats a head to head, and every other candidate
+	assert.Equal(t, b, report.CondorcetWinner)
+	assert.Equal(t, b, report.RunnerUp)
+	assert.InDelta(t, -0.2, report.Margin, utils.Epsilon)
+	// the voter for c could have elected b by voting for b
+	assert.Equal(t, []uuid.UUID{voterIDs[4]}, report.Manipulators)
+	assert.Contains(t, report.CoalitionCandidates, b)
+}
+
+func TestUnanimousVoteCantBeManipulated(t *testing.T) {
+	candidates := newIDs(3)
+	voters := make(map[uuid.UUID]voting.IVoter)
+	weights := make(map[uuid.UUID]float64)
+	for _, voter := range newIDs(4) {
+		voters[voter] = voting.IdVoteMap{candidates[0]: 0.6, candidates[1]: 0.3, candidates[2]: 0.1}
+		weights[voter] = 1.0
+	}
+
+	for method := utils.VoteMethod(0); method < utils.NumOfVoteMethods; method++ {
+		report, err := analysis.Analyse(voters, weights, func(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64) (uuid.UUID, error) {
+			return voting.WinnerFromDistWithMethod(voters, weights, method, nil)
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, candidates[0], report.Winner, method.String())
+		assert.Equal(t, candidates[0], report.CondorcetWinner, method.String())
+		assert.InDelta(t, 1.0, report.Margin, utils.Epsilon, method.String())
+		assert.Empty(t, report.Manipulators, method.String())
+		assert.Empty(t, report.CoalitionCandidates, method.String())
+	}
+}
+
+func TestAnalysisKeepsTheKnownWinner(t *testing.T) {
+	a := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	b := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	voterIDs := newIDs(2)
+	voters := map[uuid.UUID]voting.IVoter{
+		voterIDs[0]: voting.IdVoteMap{a: 1.0},
+		voterIDs[1]: voting.IdVoteMap{b: 1.0},
+	}
+	weights := map[uuid.UUID]float64{voterIDs[0]: 1.0, voterIDs[1]: 1.0}
+
+	// a random tie break elected b, replaying the vote would elect a
+	report := analysis.AnalyseOutcome(voters, weights, b, pluralityWinner)
+
+	assert.Equal(t, b, report.Winner)
+	assert.Equal(t, a, report.RunnerUp)
+	assert.InDelta(t, 0.0, report.Margin, utils.Epsilon)
+	// replays break the tie for a, so the voter for a could have changed the outcome
+	assert.Equal(t, []uuid.UUID{voterIDs[0]}, report.Manipulators)
+}
@@ -0,0 +1,177 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const trials = 200
+
+type votingMethod func(map[uuid.UUID]map[uuid.UUID]float64, map[uuid.UUID]float64, *voting.TieBreaker) uuid.UUID
+
+var methods = map[string]votingMethod{
+	"plurality":        voting.Plurality,
+	"runoff":           voting.Runoff,
+	"borda count":      voting.BordaCount,
+	"instant runoff":   voting.InstantRunoff,
+	"approval":         voting.Approval,
+	"copeland scoring": voting.CopelandScoring,
+	"schulze":          voting.Schulze,
+	"ranked pairs":     voting.RankedPairs,
+	"kemeny-young":     voting.KemenyYoung,
+	"score":            voting.Score,
+	"star":             voting.STAR,
+}
+
+func newIDs(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	return ids
+}
+
+// draws ballots where the voters often score candidates equally, so that ties are common
+func randomBallots(r *rand.Rand, voters []uuid.UUID, candidates []uuid.UUID) (map[uuid.UUID]map[uuid.UUID]float64, map[uuid.UUID]float64) {
+	ballots := make(map[uuid.UUID]map[uuid.UUID]float64, len(voters))
+	weights := make(map[uuid.UUID]float64, len(voters))
+	for _, voter := range voters {
+		ballot := make(map[uuid.UUID]float64, len(candidates))
+		for _, candidate := range candidates {
+			ballot[candidate] = float64(r.Intn(3))
+		}
+		ballots[voter] = ballot
+		weights[voter] = float64(1 + r.Intn(2))
+	}
+	return ballots, weights
+}
+
+// copies the ballots into new maps, which Go iterates in a different order
+func copyBallots(ballots map[uuid.UUID]map[uuid.UUID]float64) map[uuid.UUID]map[uuid.UUID]float64 {
+	copied := make(map[uuid.UUID]map[uuid.UUID]float64, len(ballots))
+	for voter, ballot := range ballots {
+		copied[voter] = make(map[uuid.UUID]float64, len(ballot))
+		for candidate, value := range ballot {
+			copied[voter][candidate] = value
+		}
+	}
+	return copied
+}
+
+func TestVotesAreDeterministic(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for name, method := range methods {
+		for i := 0; i < trials; i++ {
+			candidates := newIDs(2 + r.Intn(4))
+			ballots, weights := randomBallots(r, newIDs(1+r.Intn(6)), candidates)
+			winner := method(ballots, weights, voting.NewTieBreaker(utils.RandomTieBreak, rand.New(rand.NewSource(int64(i)))))
+			for j := 0; j < 5; j++ {
+				again := method(copyBallots(ballots), weights, voting.NewTieBreaker(utils.RandomTieBreak, rand.New(rand.NewSource(int64(i)))))
+				assert.Equal(t, winner, again, "%s changed its winner between identical votes", name)
+			}
+		}
+	}
+}
+
+func TestWinnerIsACandidate(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for name, method := range methods {
+		for i := 0; i < trials; i++ {
+			candidates := newIDs(1 + r.Intn(5))
+			ballots, weights := randomBallots(r, newIDs(1+r.Intn(6)), candidates)
+			winner := method(ballots, weights, nil)
+			if winner != uuid.Nil {
+				assert.Contains(t, candidates, winner, "%s elected someone who wasn't on a ballot", name)
+			}
+		}
+	}
+}
+
+// when every voter likes all candidates equally, the tie breaker alone picks the winner
+func TestIndifferentVotersLeaveItToTheTieBreaker(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for name, method := range methods {
+		for i := 0; i < trials; i++ {
+			candidates := newIDs(2 + r.Intn(4))
+			ballots := make(map[uuid.UUID]map[uuid.UUID]float64)
+			weights := make(map[uuid.UUID]float64)
+			for _, voter := range newIDs(1 + r.Intn(6)) {
+				ballots[voter] = make(map[uuid.UUID]float64)
+				for _, candidate := range candidates {
+					ballots[voter][candidate] = 1.0 / float64(len(candidates))
+				}
+				weights[voter] = 1.0
+			}
+			seniority := make([]uuid.UUID, len(candidates))
+			copy(seniority, candidates)
+			r.Shuffle(len(seniority), func(a, b int) { seniority[a], seniority[b] = seniority[b], seniority[a] })
+			incumbent := candidates[r.Intn(len(candidates))]
+
+			for rule := utils.RandomTieBreak; rule <= utils.SeniorityTieBreak; rule++ {
+				// two tie breakers with the same seed make the same draws
+				newTieBreaker := func() *voting.TieBreaker {
+					tieBreaker := voting.NewTieBreaker(rule, rand.New(rand.NewSource(int64(i))))
+					tieBreaker.Incumbent = incumbent
+					tieBreaker.Seniority = seniority
+					return tieBreaker
+				}
+				assert.Equal(t, newTieBreaker().Break(candidates), method(ballots, weights, newTieBreaker()), "%s ignored tie break rule %d", name, rule)
+			}
+		}
+	}
+}
+
+func TestLowestIDBreaksTies(t *testing.T) {
+	candidates := newIDs(3)
+	lowest := voting.NewTieBreaker(utils.LowestIDTieBreak, nil).Break(candidates)
+	for _, candidate := range candidates {
+		assert.LessOrEqual(t, lowest.String(), candidate.String())
+	}
+	// a nil tie breaker also picks the lowest UUID
+	var nilTieBreaker *voting.TieBreaker
+	assert.Equal(t, lowest, nilTieBreaker.Break(candidates))
+}
+
+func TestIncumbentWinsTies(t *testing.T) {
+	candidates := newIDs(2)
+	voters := newIDs(2)
+	ballots := map[uuid.UUID]map[uuid.UUID]float64{
+		voters[0]: {candidates[0]: 1.0},
+		voters[1]: {candidates[1]: 1.0},
+	}
+	weights := map[uuid.UUID]float64{voters[0]: 1.0, voters[1]: 1.0}
+	for name, method := range methods {
+		for _, incumbent := range candidates {
+			tieBreaker := voting.NewTieBreaker(utils.IncumbentTieBreak, nil)
+			tieBreaker.Incumbent = incumbent
+			assert.Equal(t, incumbent, method(ballots, weights, tieBreaker), name)
+		}
+	}
+}
+
+func TestSeniorityOrdersTies(t *testing.T) {
+	candidates := newIDs(4)
+	tieBreaker := voting.NewTieBreaker(utils.SeniorityTieBreak, nil)
+	tieBreaker.Seniority = []uuid.UUID{candidates[2], candidates[0]}
+
+	order := tieBreaker.Order(candidates)
+
+	assert.Equal(t, []uuid.UUID{candidates[2], candidates[0]}, order[:2])
+	// candidates without seniority follow, lowest UUID first
+	assert.ElementsMatch(t, []uuid.UUID{candidates[1], candidates[3]}, order[2:])
+	assert.Less(t, order[2].String(), order[3].String())
+}
+
+func TestSeededRandomTieBreaksRepeat(t *testing.T) {
+	candidates := newIDs(5)
+	first := voting.NewTieBreaker(utils.RandomTieBreak, rand.New(rand.NewSource(7)))
+	second := voting.NewTieBreaker(utils.RandomTieBreak, rand.New(rand.NewSource(7)))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.Order(candidates), second.Order(candidates))
+	}
+}
@@ -0,0 +1,61 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/voting"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitiseBallotRemovesInvalidEntries(t *testing.T) {
+	voter, valid, unknown := uuid.New(), uuid.New(), uuid.New()
+	isCandidate := func(id uuid.UUID) bool { return id == valid }
+
+	ballot, err := voting.SanitiseBallot(voter, map[uuid.UUID]float64{valid: 0.5, unknown: 0.5}, isCandidate)
+
+	assert.Equal(t, voting.IdVoteMap{valid: 0.5}, ballot)
+	var ballotErr *voting.BallotError
+	assert.True(t, errors.As(err, &ballotErr))
+	assert.Equal(t, voter, ballotErr.Voter)
+	assert.Equal(t, voting.UnknownCandidate, ballotErr.Problem)
+
+	_, err = voting.SanitiseBallot(voter, map[uuid.UUID]float64{valid: math.NaN()}, isCandidate)
+	assert.True(t, errors.As(err, &ballotErr))
+	assert.Equal(t, voting.InvalidValue, ballotErr.Problem)
+
+	_, err = voting.SanitiseBallot(voter, map[uuid.UUID]float64{valid: 1.0}, isCandidate)
+	assert.NoError(t, err)
+}
+
+func TestInvalidBallotsAreNotCounted(t *testing.T) {
+	honest, cheater, candidate := uuid.New(), uuid.New(), uuid.New()
+	voters := map[uuid.UUID]voting.IVoter{
+		honest:  voting.IdVoteMap{candidate: 1.0},
+		cheater: voting.IdVoteMap{uuid.Nil: 1.0},
+	}
+	weights := map[uuid.UUID]float64{honest: 1.0, cheater: 1.0}
+
+	ballots, errs := voting.SanitiseBallots(voters, nil)
+	assert.Len(t, ballots, 1)
+	assert.Len(t, errs, 1)
+
+	winner, err := voting.WinnerFromDist(voters, weights)
+	assert.NoError(t, err)
+	assert.Equal(t, candidate, winner)
+}
+
+func TestEmptyVotesReturnErrors(t *testing.T) {
+	_, err := voting.CumulativeDist(map[uuid.UUID]voting.IVoter{}, nil)
+	assert.ErrorIs(t, err, voting.ErrNoVotes)
+
+	voter := uuid.New()
+	voters := map[uuid.UUID]voting.IVoter{voter: voting.IdVoteMap{uuid.New(): 0.0}}
+	_, err = voting.CumulativeDist(voters, map[uuid.UUID]float64{voter: 1.0})
+	assert.ErrorIs(t, err, voting.ErrZeroVotes)
+
+	_, err = voting.WinnerFromDist(voters, map[uuid.UUID]float64{voter: 1.0})
+	assert.ErrorIs(t, err, voting.ErrZeroVotes)
+}
@@ -0,0 +1,114 @@
+package voting
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// adds voters that rank the candidates in the given order
+func addRankedBallots(ballots map[uuid.UUID]map[uuid.UUID]float64, weights map[uuid.UUID]float64, voters int, ranking ...uuid.UUID) {
+	for i := 0; i < voters; i++ {
+		ballot := make(map[uuid.UUID]float64, len(ranking))
+		for rank, candidate := range ranking {
+			ballot[candidate] = float64(len(ranking) - rank)
+		}
+		voter := uuid.New()
+		ballots[voter] = ballot
+		weights[voter] = 1.0
+	}
+}
+
+func TestCondorcetMethodsElectTheCondorcetWinner(t *testing.T) {
+	candidates := newIDs(3)
+	a, b, c := candidates[0], candidates[1], candidates[2]
+	ballots := make(map[uuid.UUID]map[uuid.UUID]float64)
+	weights := make(map[uuid.UUID]float64)
+	// b beats both a and c head to head, but has the fewest first preferences
+	addRankedBallots(ballots, weights, 3, a, b, c)
+	addRankedBallots(ballots, weights, 3, c, b, a)
+	addRankedBallots(ballots, weights, 1, b, a, c)
+
+	assert.NotEqual(t, b, voting.Plurality(ballots, weights, nil))
+	assert.Equal(t, b, voting.Schulze(ballots, weights, nil))
+	assert.Equal(t, b, voting.RankedPairs(ballots, weights, nil))
+	assert.Equal(t, b, voting.KemenyYoung(ballots, weights, nil))
+}
+
+func TestKemenyYoungFallsBackToSchulze(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for i := 0; i < 20; i++ {
+		ballots, weights := randomBallots(r, newIDs(5), newIDs(utils.KemenyYoungMaxCandidates+1))
+		assert.Equal(t, voting.Schulze(ballots, weights, nil), voting.KemenyYoung(ballots, weights, nil))
+	}
+}
+
+func TestStarRunoffCanOverturnTheScores(t *testing.T) {
+	candidates := newIDs(2)
+	a, b := candidates[0], candidates[1]
+	voters := newIDs(3)
+	ballots := map[uuid.UUID]map[uuid.UUID]float64{
+		voters[0]: {a: 1.0, b: 0.6},
+		voters[1]: {a: 1.0, b: 0.6},
+		voters[2]: {b: 1.0},
+	}
+	weights := map[uuid.UUID]float64{voters[0]: 1.0, voters[1]: 1.0, voters[2]: 1.0}
+
+	// b has the higher total score, but most voters prefer a
+	assert.Equal(t, b, voting.Score(ballots, weights, nil))
+	assert.Equal(t, a, voting.STAR(ballots, weights, nil))
+}
+
+func TestApportionmentSplitsTheLootIntoSeats(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for i := 0; i < 50; i++ {
+		riders := newIDs(2 + r.Intn(6))
+		voters := make(map[uuid.UUID]voting.IVoter, len(riders))
+		weights := make(map[uuid.UUID]float64, len(riders))
+		for _, rider := range riders {
+			vote := make(voting.IdVoteMap, len(riders))
+			for _, recipient := range riders {
+				vote[recipient] = r.Float64()
+			}
+			voters[rider] = vote
+			weights[rider] = 1.0
+		}
+		quotas, err := voting.CumulativeDist(voters, weights)
+		assert.NoError(t, err)
+
+		for _, method := range []utils.AllocationMethod{utils.DHONDT, utils.SAINTELAGUE} {
+			shares, err := voting.AllocationFromDist(voters, weights, method)
+			assert.NoError(t, err)
+			total := 0.0
+			for id, share := range shares {
+				total += share
+				seats := share * float64(utils.AllocationSeats)
+				assert.InDelta(t, math.Round(seats), seats, 1e-9, "shares are whole seats")
+				if method == utils.DHONDT {
+					// D'Hondt gives everyone at least the whole seats of their proportional share
+					assert.GreaterOrEqual(t, seats+1e-9, math.Floor(quotas[id]*float64(utils.AllocationSeats)))
+				}
+			}
+			assert.InDelta(t, 1.0, total, utils.Epsilon)
+		}
+	}
+}
+
+func TestDHondtFavoursTheLargestShare(t *testing.T) {
+	riders := newIDs(3)
+	voter := uuid.New()
+	voters := map[uuid.UUID]voting.IVoter{
+		voter: voting.IdVoteMap{riders[0]: 0.905, riders[1]: 0.0475, riders[2]: 0.0475},
+	}
+	weights := map[uuid.UUID]float64{voter: 1.0}
+
+	dHondt, _ := voting.DHondtDist(voters, weights)
+	sainteLague, _ := voting.SainteLagueDist(voters, weights)
+
+	assert.Greater(t, dHondt[riders[0]], sainteLague[riders[0]])
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"fmt"
+	"math"
+
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+	"github.com/google/uuid"
+)
+
+// claimed and recorded values closer than this are the same
+const auditTolerance float64 = 1e-9
+
+// keeps the ballot an agent cast in the current round, for audits to check claims about it against
+func (s *Server) recordBallots(claim objects.AuditClaimType, ballots map[uuid.UUID]voting.IVoter) {
+	if _, ok := s.castBallots[claim]; !ok {
+		s.castBallots[claim] = make(map[int]map[uuid.UUID]voting.IdVoteMap)
+	}
+	if _, ok := s.castBallots[claim][s.round]; !ok {
+		s.castBallots[claim][s.round] = make(map[uuid.UUID]voting.IdVoteMap)
+	}
+	for agentID, ballot := range ballots {
+		if ballot == nil {
+			continue
+		}
+		votes := make(voting.IdVoteMap, len(ballot.GetVotes()))
+		for candidate, value := range ballot.GetVotes() {
+			votes[candidate] = value
+		}
+		s.castBallots[claim][s.round][agentID] = votes
+	}
+}
+
+// agents pay to have the claims made to them checked, and are sent the outcome
+func (s *Server) RunAudits() {
+	for agentID, agent := range s.GetAgentMap() {
+		for _, request := range agent.DecideAudits() {
+			if agent.GetEnergyLevel() < utils.AuditCost {
+				fmt.Printf("Agent %s can't afford an audit \n", agentID)
+				break
+			}
+			agent.UpdateEnergyLevel(-utils.AuditCost)
+			msg := objects.AuditResultMessage{
+				BaseMessage: messaging.CreateMessage[objects.IBaseBiker](agent, []objects.IBaseBiker{agent}),
+				Request:     request,
+				Outcome:     s.Audit(request),
+			}
+			msg.InvokeMessageHandler(agent)
+		}
+	}
+}
+
+// checks a claim against the forces the agents applied and the ballots they cast in the round the claim is about.
+// Claims about a round the server has no record of are unverifiable
+func (s *Server) Audit(request objects.AuditRequest) objects.AuditOutcome {
+	switch request.Claim {
+	case objects.ForcesClaim:
+		forces, ok := s.appliedForces[request.Round][request.Subject]
+		if !ok {
+			return objects.ClaimUnverifiable
+		}
+		if sameForces(forces, request.Forces) {
+			return objects.ClaimTrue
+		}
+		return objects.ClaimFalse
+
+	case objects.DirectionVoteClaim, objects.RulerVoteClaim:
+		ballot, ok := s.castBallots[request.Claim][request.Round][request.Subject]
+		if !ok {
+			return objects.ClaimUnverifiable
+		}
+		if sameVotes(ballot, request.VoteMap) {
+			return objects.ClaimTrue
+		}
+		return objects.ClaimFalse
+
+	default:
+		return objects.ClaimUnverifiable
+	}
+}
+
+func sameForces(a utils.Forces, b utils.Forces) bool {
+	return math.Abs(a.Pedal-b.Pedal) <= auditTolerance &&
+		math.Abs(a.Brake-b.Brake) <= auditTolerance &&
+		a.Turning.SteerBike == b.Turning.SteerBike &&
+		math.Abs(a.Turning.SteeringForce-b.Turning.SteeringForce) <= auditTolerance
+}
+
+// candidates missing from a ballot count as a vote of zero
+func sameVotes(a voting.IdVoteMap, b voting.IdVoteMap) bool {
+	for candidate, value := range a {
+		if math.Abs(value-b[candidate]) > auditTolerance {
+			return false
+		}
+	}
+	for candidate, value := range b {
+		if math.Abs(value-a[candidate]) > auditTolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/physics"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// takes energy from an agent that broke the voting rules, the round goes on without its invalid input
+func (s *Server) penaliseAgent(bikeID uuid.UUID, agentID uuid.UUID, reason string) {
+	if agent, ok := s.GetAgentMap()[agentID]; ok {
+		agent.UpdateEnergyLevel(-utils.InvalidBallotPenalty)
+	}
+	s.logEvent(InvalidBallotEvent, bikeID, fmt.Sprintf("%s was penalised: %s", agentID, reason))
+}
+
+// removes the invalid entries of the ballots cast on a bike and penalises the agents that cast them.
+// Returns the ballots that can be counted
+func (s *Server) sanitiseBallots(bikeID uuid.UUID, voters map[uuid.UUID]voting.IVoter, isCandidate func(uuid.UUID) bool) map[uuid.UUID]voting.IVoter {
+	ballots, errs := voting.SanitiseBallots(voters, isCandidate)
+	for _, err := range errs {
+		var ballotErr *voting.BallotError
+		if errors.As(err, &ballotErr) {
+			s.penaliseAgent(bikeID, ballotErr.Voter, err.Error())
+		}
+	}
+	return ballots
+}
+
+// returns whether an agent rides a bike
+func isRider(bike objects.IMegaBike) func(uuid.UUID) bool {
+	riders := make(map[uuid.UUID]bool, len(bike.GetAgents()))
+	for _, agent := range bike.GetAgents() {
+		riders[agent.GetID()] = true
+	}
+	return func(id uuid.UUID) bool {
+		return riders[id]
+	}
+}
+
+// keeps the valid agents among those named by a decision on a bike, in order and without repeats. The agent that took
+// the decision is penalised for naming anyone else, decisions taken by a vote (decider uuid.Nil) have nobody to blame
+func (s *Server) sanitiseDecision(bikeID uuid.UUID, decider uuid.UUID, ids []uuid.UUID, isValid func(uuid.UUID) bool) []uuid.UUID {
+	sanitised := make([]uuid.UUID, 0, len(ids))
+	var invalid []uuid.UUID
+	for _, id := range ids {
+		if !isValid(id) {
+			invalid = append(invalid, id)
+		} else if !slices.Contains(sanitised, id) {
+			sanitised = append(sanitised, id)
+		}
+	}
+	if len(invalid) != 0 {
+		if decider != uuid.Nil {
+			s.penaliseAgent(bikeID, decider, fmt.Sprintf("named agents that can't be chosen %v", invalid))
+		} else {
+			fmt.Printf("Bike %s chose agents that can't be chosen %v \n", bikeID, invalid)
+		}
+	}
+	return sanitised
+}
+
+// removes the entries of a governance ballot that are negative, not numbers, or for a governance that has no protocol,
+// and the whole ballot if what is left is worth more than one vote. The voter is penalised once for any of them
+func (s *Server) sanitiseGovernanceVote(bikeID uuid.UUID, voter uuid.UUID, vote voting.GovernanceVote) voting.GovernanceVote {
+	sanitised := make(voting.GovernanceVote, len(vote))
+	var invalid []utils.Governance
+	total := 0.0
+	for governance, share := range vote {
+		if _, ok := s.GetGovernanceProtocol(governance); !ok || share < 0 || math.IsNaN(share) || math.IsInf(share, 0) {
+			invalid = append(invalid, governance)
+			continue
+		}
+		sanitised[governance] = share
+		total += share
+	}
+	switch {
+	case total > 1.0+utils.Epsilon:
+		s.penaliseAgent(bikeID, voter, fmt.Sprintf("cast %.2f votes on the governance", total))
+		return voting.GovernanceVote{}
+	case len(invalid) != 0:
+		s.penaliseAgent(bikeID, voter, fmt.Sprintf("voted for governances that can't be chosen %v", invalid))
+	}
+	return sanitised
+}
+
+// removes the vote weights given to agents that aren't riding the bike, and weights that are negative or not numbers.
+// The ruler of the bike, who decided the weights, is penalised for them
+func (s *Server) sanitiseWeights(bike objects.IMegaBike, weights map[uuid.UUID]float64) map[uuid.UUID]float64 {
+	riders := make(map[uuid.UUID]bool)
+	for _, agent := range bike.GetAgents() {
+		riders[agent.GetID()] = true
+	}
+	sanitised := make(map[uuid.UUID]float64, len(weights))
+	var invalid []uuid.UUID
+	for id, weight := range weights {
+		if !riders[id] || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			invalid = append(invalid, id)
+			continue
+		}
+		sanitised[id] = weight
+	}
+	if len(invalid) != 0 && bike.GetRuler() != uuid.Nil {
+		s.penaliseAgent(bike.GetID(), bike.GetRuler(), fmt.Sprintf("gave invalid vote weights to %v", invalid))
+	}
+	return sanitised
+}
+
+// the direction of a bike whose riders cast no valid vote: the lootbox it was heading to, otherwise the nearest one
+func (s *Server) fallbackDirection(bike objects.IMegaBike) uuid.UUID {
+	if _, ok := s.lootBoxes[s.lastDirections[bike.GetID()]]; ok {
+		return s.lastDirections[bike.GetID()]
+	}
+	nearest, minDistance := uuid.Nil, math.MaxFloat64
+	for id, lootBox := range s.lootBoxes {
+		if distance := physics.ComputeDistance(bike.GetPosition(), lootBox.GetPosition()); distance < minDistance {
+			nearest, minDistance = id, distance
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/physics"
+	"SOMAS2023/internal/common/utils"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) SetColourPolicy(policy utils.ColourPolicy) {
+	s.colourPolicy = policy
+}
+
+// returns the colours the agent has sought in the current game, oldest first
+func (s *Server) GetColourHistory(agent objects.IBaseBiker) []utils.Colour {
+	history, ok := s.colourHistory[agent.GetID()]
+	if !ok {
+		return []utils.Colour{agent.GetColour()}
+	}
+	return append(make([]utils.Colour, 0, len(history)), history...)
+}
+
+func (s *Server) recordColourChange(agentID uuid.UUID, previous utils.Colour, colour utils.Colour) {
+	history, ok := s.colourHistory[agentID]
+	if !ok {
+		history = []utils.Colour{previous}
+	}
+	if colour != previous {
+		history = append(history, colour)
+	}
+	s.colourHistory[agentID] = history
+}
+
+// sets the colour sought by the agent and records it in its colour history
+func (s *Server) setAgentColour(agent objects.IBaseBiker, colour utils.Colour) {
+	previous := agent.GetColour()
+	agent.SetColour(colour)
+	s.recordColourChange(agent.GetID(), previous, colour)
+}
+
+// returns the colour of the lootbox nearest to the agent's bike that doesn't have the colour the agent is seeking.
+// the second return value is false if there is no such lootbox
+func (s *Server) nearestOtherColour(agent objects.IBaseBiker) (utils.Colour, bool) {
+	bike, ok := s.megaBikes[agent.GetBike()]
+	if !ok {
+		return agent.GetColour(), false
+	}
+	nearest, minDistance := agent.GetColour(), math.MaxFloat64
+	for _, lootBox := range s.lootBoxes {
+		if lootBox.GetColour() == agent.GetColour() {
+			continue
+		}
+		if distance := physics.ComputeDistance(bike.GetPosition(), lootBox.GetPosition()); distance < minDistance {
+			nearest, minDistance = lootBox.GetColour(), distance
+		}
+	}
+	return nearest, minDistance < math.MaxFloat64
+}
+
+// changes the colour sought by an agent that has just looted a box of its colour, according to the colour policy
+func (s *Server) UpdateAgentColour(agent objects.IBaseBiker) {
+	switch s.colourPolicy {
+	case utils.NearestColour:
+		if colour, ok := s.nearestOtherColour(agent); ok {
+			s.setAgentColour(agent, colour)
+			return
+		}
+		// no lootbox of another colour is left, fall back to the agent's choice
+		fallthrough
+	case utils.RandomColour:
+		previous := agent.GetColour()
+		agent.UpdateColour(utils.NumOfColours)
+		s.recordColourChange(agent.GetID(), previous, agent.GetColour())
+	case utils.RotatingColour:
+		s.setAgentColour(agent, (agent.GetColour()+1)%utils.NumOfColours)
+	}
+}
+
+// updates the colour of the agents that looted a box of their colour, once the looted boxes are gone
+func (s *Server) updateAgentColours(collectors map[uuid.UUID]struct{}) {
+	for agentID := range collectors {
+		if agent, ok := s.GetAgentMap()[agentID]; ok {
+			s.UpdateAgentColour(agent)
+		}
+	}
+}
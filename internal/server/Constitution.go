@@ -0,0 +1,75 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"fmt"
+	"math"
+)
+
+// returns the share of the riders that must vote for a governance for the bike to switch to it
+func (s *Server) governanceChangeThreshold(governance utils.Governance) float64 {
+	protocol, ok := s.GetGovernanceProtocol(governance)
+	if !ok {
+		// no share of the riders is enough to switch to an unknown governance
+		return math.Inf(1)
+	}
+	return protocol.ChangeThreshold()
+}
+
+// checks whether a bike is due a constitutional vote this round
+func (s *Server) constitutionalVoteDue(bike objects.IMegaBike) bool {
+	if len(bike.GetAgents()) == 0 || s.round%utils.ConstitutionalVotePeriod != 0 {
+		return false
+	}
+	lastChange, changed := s.lastGovernanceChange[bike.GetID()]
+	return !changed || s.round-lastChange >= utils.GovernanceChangeCooldown
+}
+
+// sets the governance of a bike and appoints its new ruler
+func (s *Server) changeGovernance(bike objects.IMegaBike, governance utils.Governance) {
+	bike.SetGovernance(governance)
+	s.lastGovernanceChange[bike.GetID()] = s.round
+	s.UpdateGameStates() // agents need an updated game state to elect a ruler
+	s.appointRuler(bike)
+}
+
+// holds a vote among the riders of a bike on its voting methods and its governance. The governance changes if the most
+// voted governance differs from the current one and gets at least the share of the votes required to switch to it
+func (s *Server) RunConstitutionalVote(bike objects.IMegaBike) {
+	s.RunVoteMethodVote(bike)
+
+	agents := bike.GetAgents()
+	votes := make([]voting.GovernanceVote, 0, len(agents))
+	for _, agent := range agents {
+		votes = append(votes, s.sanitiseGovernanceVote(bike.GetID(), agent.GetID(), agent.VoteGovernance()))
+	}
+	winner, err := voting.WinnerFromGovernance(votes)
+	if err != nil {
+		fmt.Printf("Constitutional vote on bike %s failed: %s \n", bike.GetID(), err)
+		return
+	}
+
+	support := 0.0
+	for _, vote := range votes {
+		support += vote[winner]
+	}
+	share := support / float64(len(votes))
+	current := bike.GetGovernance()
+	if winner == current || share < s.governanceChangeThreshold(winner)-utils.Epsilon {
+		s.logEvent(ConstitutionalVoteEvent, bike.GetID(), fmt.Sprintf("kept %s governance (%.2f of the vote for %s)", current, share, winner))
+		return
+	}
+	s.changeGovernance(bike, winner)
+	s.logEvent(GovernanceChangeEvent, bike.GetID(), fmt.Sprintf("changed governance from %s to %s with %.2f of the vote", current, winner, share))
+}
+
+// holds the constitutional votes of the bikes that are due one
+func (s *Server) RunConstitutionalVotes() {
+	for _, bike := range s.GetMegaBikes() {
+		if s.constitutionalVoteDue(bike) {
+			s.RunConstitutionalVote(bike)
+		}
+	}
+}
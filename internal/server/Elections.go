@@ -0,0 +1,109 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// the term a ruler is serving on a bike
+type RulerTerm struct {
+	Ruler uuid.UUID `json:"ruler"`
+	Start int       `json:"start"` // the round the current term started in
+	Terms int       `json:"terms"` // the number of consecutive terms the ruler has been elected for
+}
+
+// returns the term of the ruler of a bike. The second return value is false if the bike has no ruler
+func (s *Server) GetRulerTerm(bikeID uuid.UUID) (RulerTerm, bool) {
+	term, ok := s.rulerTerms[bikeID]
+	return term, ok
+}
+
+// sets the ruler of a bike and keeps track of the terms they served
+func (s *Server) setRuler(bike objects.IMegaBike, ruler uuid.UUID) {
+	bike.SetRuler(ruler)
+	if ruler == uuid.Nil {
+		delete(s.rulerTerms, bike.GetID())
+		return
+	}
+	term, ok := s.rulerTerms[bike.GetID()]
+	if ok && term.Ruler == ruler {
+		term.Terms++
+	} else {
+		term = RulerTerm{Ruler: ruler, Terms: 1}
+	}
+	term.Start = s.round
+	s.rulerTerms[bike.GetID()] = term
+}
+
+// returns the riders of a bike standing for election, excluding a leader that has reached the term limit
+func (s *Server) getCandidates(bike objects.IMegaBike) []objects.IBaseBiker {
+	term, hasRuler := s.rulerTerms[bike.GetID()]
+	candidates := make([]objects.IBaseBiker, 0)
+	for _, agent := range bike.GetAgents() {
+		if hasRuler && agent.GetID() == term.Ruler && term.Terms >= utils.LeaderTermLimit {
+			continue
+		}
+		if agent.DecideCandidacy() {
+			candidates = append(candidates, agent)
+		}
+	}
+	return candidates
+}
+
+// candidates announce their platform to the other riders of the bike. Campaign messages are sent like any other
+// message, and are delivered before the vote unless the channel delays them
+func (s *Server) runCampaign(candidates []objects.IBaseBiker) {
+	for _, candidate := range candidates {
+		s.sendMessage(candidate, candidate.CreateCampaignMessage(), utils.Campaign)
+	}
+	s.deliverMessages(utils.Campaign)
+}
+
+// holds an election on a Leadership bike: candidates campaign, then the riders elect one of them.
+// if nobody stands, every rider apart from a leader that reached the term limit can be elected
+func (s *Server) RunLeadershipElection(bike objects.IMegaBike) {
+	agents := bike.GetAgents()
+	if len(agents) == 0 {
+		return
+	}
+	candidates := s.getCandidates(bike)
+	s.runCampaign(candidates)
+
+	candidateIDs := make([]uuid.UUID, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateIDs = append(candidateIDs, candidate.GetID())
+	}
+	if len(candidateIDs) == 0 {
+		term, hasRuler := s.rulerTerms[bike.GetID()]
+		for _, agent := range agents {
+			if !hasRuler || agent.GetID() != term.Ruler || term.Terms < utils.LeaderTermLimit {
+				candidateIDs = append(candidateIDs, agent.GetID())
+			}
+		}
+	}
+	if len(candidateIDs) == 0 {
+		// the leader is the only rider left, they stay in power
+		candidateIDs = append(candidateIDs, bike.GetRuler())
+	}
+
+	previous := bike.GetRuler()
+	ruler := s.electRuler(agents, utils.Leadership, candidateIDs, bike.GetVoteMethod(utils.Election), s.tieBreaker(previous, agents))
+	s.setRuler(bike, ruler)
+	s.logEvent(LeadershipElectionEvent, bike.GetID(), fmt.Sprintf("%s was elected leader out of %d candidates (previous leader %s)", ruler, len(candidateIDs), previous))
+}
+
+// holds an election on every bike whose rulers have come to the end of their term: a new vote in leadership and council
+// bikes, the next ruler in rotating leadership and sortition bikes
+func (s *Server) RunScheduledElections() {
+	for _, bike := range s.GetMegaBikes() {
+		if len(bike.GetAgents()) == 0 {
+			continue
+		}
+		if protocol := s.governanceProtocol(bike); protocol.ElectionDue(s, bike) {
+			protocol.Election(s, bike)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) GetEnergyLedger() []objects.EnergyTransaction {
+	return slices.Clone(s.energyLedger)
+}
+
+// returns the loans that haven't been repaid yet, ordered by due round
+func (s *Server) GetOutstandingLoans() []objects.EnergyLoan {
+	loans := make([]objects.EnergyLoan, 0, len(s.loans))
+	for _, loan := range s.loans {
+		loans = append(loans, loan)
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		if loans[i].DueRound != loans[j].DueRound {
+			return loans[i].DueRound < loans[j].DueRound
+		}
+		return loans[i].ID.String() < loans[j].ID.String()
+	})
+	return loans
+}
+
+// records a transaction in the energy ledger. Transactions of no energy aren't recorded
+func (s *Server) recordEnergyTransaction(transactionType objects.EnergyTransactionType, from uuid.UUID, to uuid.UUID, amount float64) {
+	if amount <= 0.0 {
+		return
+	}
+	s.energyLedger = append(s.energyLedger, objects.EnergyTransaction{
+		Round:  s.round,
+		Type:   transactionType,
+		From:   from,
+		To:     to,
+		Amount: amount,
+	})
+}
+
+// moves energy from one agent to another and returns the energy the receiver was credited with.
+// the receiver can't go above its maximum level, and the giver keeps what the receiver can't take
+func transferEnergy(from objects.IBaseBiker, to objects.IBaseBiker, amount float64) float64 {
+	before := to.GetEnergyLevel()
+	to.UpdateEnergyLevel(amount)
+	credited := to.GetEnergyLevel() - before
+	from.UpdateEnergyLevel(-credited)
+	return credited
+}
+
+// checks that an offer is made by the agent giving the energy, to another living agent, and that the giver can afford it
+func (s *Server) isValidEnergyTransferOffer(offer objects.EnergyTransferOffer, giver objects.IBaseBiker) bool {
+	if offer.From != giver.GetID() || offer.To == offer.From {
+		return false
+	}
+	if _, alive := s.GetAgentMap()[offer.To]; !alive {
+		return false
+	}
+	if offer.Amount <= 0.0 || offer.Amount > giver.GetEnergyLevel() {
+		return false
+	}
+	if offer.Loan && (offer.InterestRate < 0.0 || offer.Term < 1) {
+		return false
+	}
+	return true
+}
+
+// collects the loans that are due. Borrowers repay as much as they can, anything they can't repay is recorded as a default.
+// loans are collected in ID order, so that a borrower that can't repay all its loans repays the same lenders every time
+func (s *Server) collectDueLoans() {
+	for _, id := range sortedKeys(s.loans) {
+		loan := s.loans[id]
+		if loan.DueRound > s.round {
+			continue
+		}
+		delete(s.loans, id)
+		lender, lenderAlive := s.GetAgentMap()[loan.Lender]
+		if !lenderAlive {
+			// the debt dies with the lender
+			continue
+		}
+		owed := loan.AmountOwed()
+		repaid := 0.0
+		if borrower, ok := s.GetAgentMap()[loan.Borrower]; ok {
+			repaid = min(owed, max(borrower.GetEnergyLevel(), 0.0))
+			credited := transferEnergy(borrower, lender, repaid)
+			s.recordEnergyTransaction(objects.LoanRepayment, loan.Borrower, loan.Lender, credited)
+		}
+		if repaid < owed {
+			fmt.Printf("Agent %s defaulted on a loan from agent %s \n", loan.Borrower, loan.Lender)
+			s.recordEnergyTransaction(objects.LoanDefault, loan.Borrower, loan.Lender, owed-repaid)
+		}
+	}
+}
+
+// repays the loans that are due, then lets agents give or lend energy to each other.
+// a transfer only happens if the receiving agent accepts it. Givers are visited in ID order,
+// as what they can afford depends on the transfers made before theirs
+func (s *Server) RunEnergyTransfers() {
+	s.collectDueLoans()
+
+	for _, giverID := range sortedKeys(s.GetAgentMap()) {
+		giver := s.GetAgentMap()[giverID]
+		for _, offer := range giver.DecideEnergyTransfers() {
+			if !s.isValidEnergyTransferOffer(offer, giver) {
+				continue
+			}
+			receiver := s.GetAgentMap()[offer.To]
+			if !receiver.DecideEnergyTransferResponse(offer) {
+				continue
+			}
+			credited := transferEnergy(giver, receiver, offer.Amount)
+			if credited <= 0.0 {
+				continue
+			}
+			if offer.Loan {
+				// the borrower only owes what it received
+				loan := objects.EnergyLoan{
+					ID:           uuid.New(),
+					Lender:       offer.From,
+					Borrower:     offer.To,
+					Principal:    credited,
+					InterestRate: offer.InterestRate,
+					DueRound:     s.round + offer.Term,
+				}
+				s.loans[loan.ID] = loan
+				s.recordEnergyTransaction(objects.LoanTransfer, offer.From, offer.To, credited)
+			} else {
+				s.recordEnergyTransaction(objects.GiftTransfer, offer.From, offer.To, credited)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type EventType int
+
+const (
+	ConstitutionalVoteEvent EventType = iota // a bike held a constitutional vote that didn't change its governance
+	GovernanceChangeEvent                    // a bike changed its governance
+	RecallVoteEvent                          // a bike held a vote of no confidence that kept its ruler in power
+	RulerRecalledEvent                       // a ruler was removed by a vote of no confidence
+	LeadershipElectionEvent                  // a bike held a scheduled election of its leader
+	VoteMethodChangeEvent                    // a bike changed the voting method of a decision
+	InvalidBallotEvent                       // an agent was penalised for an invalid ballot, proposal or weighting
+	RuleVoteEvent                            // a bike rejected a change to its rules
+	RuleChangeEvent                          // a bike enacted, amended or repealed a rule
+	RuleSanctionEvent                        // a rider was fined or kicked out for breaking the rules of its bike
+)
+
+// something that happened to the institutions of a bike, recorded by the server
+type GameEvent struct {
+	Round       int       `json:"round"`
+	Type        EventType `json:"type"`
+	BikeID      uuid.UUID `json:"bike_id"`
+	Description string    `json:"description"`
+}
+
+func (s *Server) GetEvents() []GameEvent {
+	return slices.Clone(s.events)
+}
+
+// returns the events recorded in the current round
+func (s *Server) getRoundEvents() []GameEvent {
+	events := make([]GameEvent, 0)
+	for _, event := range s.events {
+		if event.Round == s.round {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (s *Server) logEvent(eventType EventType, bikeID uuid.UUID, description string) {
+	fmt.Printf("Bike %s: %s \n", bikeID, description)
+	s.events = append(s.events, GameEvent{
+		Round:       s.round,
+		Type:        eventType,
+		BikeID:      bikeID,
+		Description: description,
+	})
+}
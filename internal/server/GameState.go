@@ -29,6 +29,10 @@ func (s *Server) GetJoiningRequests(inLimbo []uuid.UUID) map[uuid.UUID][]uuid.UU
 		// don't process joining requests of agents in limbo
 		if !agent.GetBikeStatus() && !slices.Contains(inLimbo, agentID) {
 			bike := agent.GetBike()
+			// agents acting on old news can head for a bike that is gone
+			if _, ok := s.megaBikes[bike]; !ok {
+				continue
+			}
 			if ids, ok := bikeRequests[bike]; ok {
 				bikeRequests[bike] = append(ids, agentID)
 			} else {
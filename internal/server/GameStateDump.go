@@ -16,6 +16,13 @@ type GameStateDump struct {
 	Bikes     map[uuid.UUID]BikeDump    `json:"bikes"`
 	LootBoxes map[uuid.UUID]LootBoxDump `json:"loot_boxes"`
 	Audi      AudiDump                  `json:"audi"`
+
+	EnergyLedger []objects.EnergyTransaction `json:"energy_ledger"`
+	Loans        []objects.EnergyLoan        `json:"loans"`
+	PointsLedger []objects.PointsTransaction `json:"points_ledger"`
+	Events       []GameEvent                 `json:"events"`        // events of the current round
+	VoteAnalyses []VoteAnalysis              `json:"vote_analyses"` // analyses of the votes of the current round
+	Messages     []MessageRecord             `json:"-"`             // messages delivered in the current round, written to the message log
 }
 
 type PhysicsObjectDump struct {
@@ -31,26 +38,47 @@ type BikeDump struct {
 	AgentIDs   []uuid.UUID      `json:"agent_ids"`
 	Governance utils.Governance `json:"governance"`
 	Ruler      uuid.UUID        `json:"ruler"`
+	RulerTerm  RulerTerm        `json:"ruler_term"`
+	Council    []uuid.UUID      `json:"council"`
+	// VoteMethods maps the decisions the riders vote on to the voting method used for them
+	VoteMethods map[utils.Action]utils.VoteMethod `json:"vote_methods"`
+	// WeightingPolicy is how the votes of the riders are weighed while the bike is a democracy
+	WeightingPolicy utils.WeightingPolicy `json:"weighting_policy"`
+	// VoteWeights maps the riders of a democracy to the weight the policy gives their votes
+	VoteWeights map[uuid.UUID]float64 `json:"vote_weights"`
+	// Notices are the notice board of the bike
+	Notices []objects.Notice `json:"notices"`
+	// Rules are the rules in force on the bike
+	Rules []objects.Rule `json:"rules"`
+	// RuleViolations maps the riders that broke the rules of the bike to the number of times they did
+	RuleViolations map[uuid.UUID]int `json:"rule_violations"`
 }
 
 type AgentDump struct {
-	ID           uuid.UUID             `json:"-"`
-	Class        string                `json:"class"`
-	Forces       utils.Forces          `json:"forces"`
-	EnergyLevel  float64               `json:"energy_level"`
-	Points       int                   `json:"points"`
-	Colour       utils.Colour          `json:"-"`
-	ColourString string                `json:"colour"`
-	Location     utils.Coordinates     `json:"location"`
-	OnBike       bool                  `json:"on_bike"`
-	BikeID       uuid.UUID             `json:"bike_id"`
-	Reputation   map[uuid.UUID]float64 `json:"reputation"`
-	GroupID      int                   `json:"group_id"`
+	ID            uuid.UUID             `json:"-"`
+	Class         string                `json:"class"`
+	Forces        utils.Forces          `json:"forces"`
+	EnergyLevel   float64               `json:"energy_level"`
+	Points        int                   `json:"points"`
+	Colour        utils.Colour          `json:"-"`
+	ColourString  string                `json:"colour"`
+	ColourHistory []string              `json:"colour_history"`
+	Location      utils.Coordinates     `json:"location"`
+	OnBike        bool                  `json:"on_bike"`
+	BikeID        uuid.UUID             `json:"bike_id"`
+	Reputation    map[uuid.UUID]float64 `json:"reputation"`
+	GroupID       int                   `json:"group_id"`
+	// deliveries of the agent's messages the server dropped in the current game, see utils.MessagingLimits
+	MessagesDropped int `json:"messages_dropped"`
+	// whether the forces can be read, only in the view of an agent that observes them late, see utils.SensorModel
+	forcesObserved bool
 }
 
 type LootBoxDump struct {
 	PhysicsObjectDump
 	TotalResources float64      `json:"total_resources"`
+	MinResources   float64      `json:"-"` // the range the resources are reported in, see utils.SensorModel
+	MaxResources   float64      `json:"-"`
 	Colour         utils.Colour `json:"-"`
 	ColourString   string       `json:"colour"`
 }
@@ -70,6 +98,14 @@ func newPhysicsObjectDump(physicsObject objects.IPhysicsObject) PhysicsObjectDum
 	}
 }
 
+func colourStrings(colours []utils.Colour) []string {
+	strs := make([]string, len(colours))
+	for i, colour := range colours {
+		strs[i] = colour.String()
+	}
+	return strs
+}
+
 func (s *Server) NewGameStateDump(iteration int) GameStateDump {
 	agents := make(map[uuid.UUID]AgentDump, len(s.GetAgentMap()))
 	for id, agent := range s.GetAgentMap() {
@@ -80,18 +116,20 @@ func (s *Server) NewGameStateDump(iteration int) GameStateDump {
 			location = utils.Coordinates{X: 0.0, Y: 0.0}
 		}
 		agents[id] = AgentDump{
-			ID:           agent.GetID(),
-			Class:        strings.TrimPrefix(reflect.TypeOf(agent).String(), "*"),
-			Forces:       agent.GetForces(),
-			EnergyLevel:  agent.GetEnergyLevel(),
-			Points:       agent.GetPoints(),
-			Colour:       agent.GetColour(),
-			ColourString: agent.GetColour().String(),
-			Location:     location,
-			OnBike:       agent.GetBikeStatus(),
-			BikeID:       agent.GetBike(),
-			Reputation:   maps.Clone(agent.GetReputation()),
-			GroupID:      agent.GetGroupID(),
+			ID:              agent.GetID(),
+			Class:           strings.TrimPrefix(reflect.TypeOf(agent).String(), "*"),
+			Forces:          agent.GetForces(),
+			EnergyLevel:     agent.GetEnergyLevel(),
+			Points:          agent.GetPoints(),
+			Colour:          agent.GetColour(),
+			ColourString:    agent.GetColour().String(),
+			ColourHistory:   colourStrings(s.GetColourHistory(agent)),
+			Location:        location,
+			OnBike:          agent.GetBikeStatus(),
+			BikeID:          agent.GetBike(),
+			Reputation:      maps.Clone(agent.GetReputation()),
+			GroupID:         agent.GetGroupID(),
+			MessagesDropped: s.droppedMessages[id],
 		}
 	}
 
@@ -103,12 +141,27 @@ func (s *Server) NewGameStateDump(iteration int) GameStateDump {
 			agentDumps = append(agentDumps, agents[agent.GetID()])
 			agentIDs = append(agentIDs, agent.GetID())
 		}
+		var voteWeights map[uuid.UUID]float64
+		if bike.GetGovernance() == utils.Democracy {
+			voteWeights = s.policyWeights(bike)
+		}
 		bikes[id] = BikeDump{
 			PhysicsObjectDump: newPhysicsObjectDump(bike),
 			Agents:            agentDumps,
 			AgentIDs:          agentIDs,
 			Governance:        bike.GetGovernance(),
 			Ruler:             bike.GetRuler(),
+			RulerTerm:         s.rulerTerms[id],
+			Council:           bike.GetCouncil(),
+			VoteMethods: map[utils.Action]utils.VoteMethod{
+				utils.Direction: bike.GetVoteMethod(utils.Direction),
+				utils.Election:  bike.GetVoteMethod(utils.Election),
+			},
+			WeightingPolicy: bike.GetWeightingPolicy(),
+			VoteWeights:     voteWeights,
+			Notices:         bike.GetNotices(),
+			Rules:           bike.GetRules(),
+			RuleViolations:  maps.Clone(s.ruleViolations[id]),
 		}
 	}
 
@@ -117,6 +170,8 @@ func (s *Server) NewGameStateDump(iteration int) GameStateDump {
 		lootBoxes[id] = LootBoxDump{
 			PhysicsObjectDump: newPhysicsObjectDump(lootBox),
 			TotalResources:    lootBox.GetTotalResources(),
+			MinResources:      lootBox.GetTotalResources(),
+			MaxResources:      lootBox.GetTotalResources(),
 			Colour:            lootBox.GetColour(),
 			ColourString:      lootBox.GetColour().String(),
 		}
@@ -132,5 +187,11 @@ func (s *Server) NewGameStateDump(iteration int) GameStateDump {
 			ID:                s.audi.GetID(),
 			TargetBike:        s.audi.GetTargetID(),
 		},
+		EnergyLedger: s.GetEnergyLedger(),
+		Loans:        s.GetOutstandingLoans(),
+		PointsLedger: s.GetPointsLedger(),
+		Events:       s.getRoundEvents(),
+		VoteAnalyses: s.getRoundVoteAnalyses(),
+		Messages:     s.getRoundMessages(),
 	}
 }
@@ -47,8 +47,12 @@ func (a AgentDump) DecideForce(uuid.UUID) {
 	panic(bannedFunctionErrorMessage)
 }
 
+// the forces of other agents can only be read when they are observed late, see utils.SensorModel
 func (a AgentDump) GetForces() utils.Forces {
-	panic(bannedFunctionErrorMessage)
+	if !a.forcesObserved {
+		panic(bannedFunctionErrorMessage)
+	}
+	return a.Forces
 }
 
 func (a AgentDump) DecideJoining([]uuid.UUID) map[uuid.UUID]bool {
@@ -177,6 +181,106 @@ func (a AgentDump) DecideWeights(action utils.Action) map[uuid.UUID]float64 {
 	panic(bannedFunctionErrorMessage)
 }
 
+func (a AgentDump) DecideNegotiationOffers(map[uuid.UUID][]uuid.UUID) []objects.NegotiationOfferMessage {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) RespondToNegotiationOffer(objects.NegotiationOfferMessage) bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) HandleNegotiationOfferMessage(msg objects.NegotiationOfferMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideEnergyTransfers() []objects.EnergyTransferOffer {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideEnergyTransferResponse(objects.EnergyTransferOffer) bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecidePointsPurchases() []objects.PointsPurchase {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) SetColour(utils.Colour) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) VoteGovernance() voting.GovernanceVote {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideVoteMethod(utils.Action) utils.VoteMethod {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideRecall() bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) VoteRecall() bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideCandidacy() bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) CreateCampaignMessage() objects.CampaignMessage {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) HandleCampaignMessage(objects.CampaignMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideAudits() []objects.AuditRequest {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) DecideNoticeBoardPosts() []objects.NoticeBoardPost {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) ProposeRuleAmendments() []objects.RuleAmendment {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) VoteRuleAmendment(objects.RuleAmendment) bool {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) HandleAuditResultMessage(objects.AuditResultMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) HandleGenericMessage(objects.GenericMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) HandleDeliveryReceiptMessage(objects.DeliveryReceiptMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) GetMessagingPhase() utils.MessagingPhase {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) SetMessagingPhase(utils.MessagingPhase) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) GetInbox() []objects.InboxMessage {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (a AgentDump) ReceiveMessage(objects.InboxMessage) {
+	panic(bannedFunctionErrorMessage)
+}
+
 func (a AgentDump) ResetPoints() {
 	panic(bannedFunctionErrorMessage)
 }
@@ -205,6 +309,26 @@ func (b BikeDump) SetRuler(uuid.UUID) {
 	panic(bannedFunctionErrorMessage)
 }
 
+func (b BikeDump) SetCouncil([]uuid.UUID) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (b BikeDump) SetVoteMethod(utils.Action, utils.VoteMethod) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (b BikeDump) SetWeightingPolicy(utils.WeightingPolicy) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (b BikeDump) SetNotices([]objects.Notice) {
+	panic(bannedFunctionErrorMessage)
+}
+
+func (b BikeDump) SetRules([]objects.Rule) {
+	panic(bannedFunctionErrorMessage)
+}
+
 func (a AudiDump) UpdateGameState(objects.IGameState) {
 	panic(bannedFunctionErrorMessage)
 }
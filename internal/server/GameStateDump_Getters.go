@@ -4,6 +4,7 @@ import (
 	"SOMAS2023/internal/common/objects"
 	"SOMAS2023/internal/common/utils"
 	"maps"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -36,6 +37,18 @@ func (gs GameStateDump) GetAudi() objects.IAudi {
 	return gs.Audi
 }
 
+func (gs GameStateDump) GetEnergyLedger() []objects.EnergyTransaction {
+	return slices.Clone(gs.EnergyLedger)
+}
+
+func (gs GameStateDump) GetOutstandingLoans() []objects.EnergyLoan {
+	return slices.Clone(gs.Loans)
+}
+
+func (gs GameStateDump) GetPointsLedger() []objects.PointsTransaction {
+	return slices.Clone(gs.PointsLedger)
+}
+
 func (o PhysicsObjectDump) GetID() uuid.UUID {
 	return o.ID
 }
@@ -112,10 +125,29 @@ func (b BikeDump) GetRuler() uuid.UUID {
 	return b.Ruler
 }
 
+func (b BikeDump) GetCouncil() []uuid.UUID {
+	return slices.Clone(b.Council)
+}
+
+func (b BikeDump) GetVoteMethod(action utils.Action) utils.VoteMethod {
+	if method, ok := b.VoteMethods[action]; ok {
+		return method
+	}
+	return utils.VoteAction
+}
+
+func (b BikeDump) GetWeightingPolicy() utils.WeightingPolicy {
+	return b.WeightingPolicy
+}
+
 func (l LootBoxDump) GetTotalResources() float64 {
 	return l.TotalResources
 }
 
+func (l LootBoxDump) GetResourceRange() (float64, float64) {
+	return l.MinResources, l.MaxResources
+}
+
 func (l LootBoxDump) GetColour() utils.Colour {
 	return l.Colour
 }
@@ -123,3 +155,11 @@ func (l LootBoxDump) GetColour() utils.Colour {
 func (a AudiDump) GetTargetID() uuid.UUID {
 	return a.TargetBike
 }
+
+func (b BikeDump) GetNotices() []objects.Notice {
+	return slices.Clone(b.Notices)
+}
+
+func (b BikeDump) GetRules() []objects.Rule {
+	return slices.Clone(b.Rules)
+}
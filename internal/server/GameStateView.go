@@ -0,0 +1,313 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/physics"
+	"SOMAS2023/internal/common/utils"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// the game state as one agent sees it. Under partial observability its fellow riders are shown in full, the agents
+// on other bikes within utils.VisionRadius and those asking to join its bike only in part, with a noisy energy level,
+// and the others not at all. Bikes, lootboxes and the Audi can be seen from anywhere, but only the riders the agent
+// can see are listed on a bike. As under full observability, only the notice boards the agent can read are shown.
+// On top of that, the sensor model of the server blurs positions and lootbox resources, and delays the forces of
+// the agents seen in full
+type GameStateView struct {
+	observer  uuid.UUID
+	agents    map[uuid.UUID]AgentDump
+	bikes     map[uuid.UUID]BikeDump
+	lootBoxes map[uuid.UUID]LootBoxDump
+	audi      AudiDump
+
+	energyLedger []objects.EnergyTransaction
+	loans        []objects.EnergyLoan
+	pointsLedger []objects.PointsTransaction
+}
+
+// sets whether agents see the whole game state or only what is around them
+func (s *Server) SetObservability(observability utils.Observability) {
+	s.observability = observability
+}
+
+// sets how accurately agents sense the game state
+func (s *Server) SetSensorModel(sensors utils.SensorModel) {
+	s.sensors = sensors
+}
+
+// returns the game state the agent gets at the start of each step
+func (s *Server) gameStateFor(agent objects.IBaseBiker, gs GameStateDump) objects.IGameState {
+	if s.observability == utils.FullObservability && s.sensors == (utils.SensorModel{}) {
+		return withReadableNotices(gs, agent.GetID())
+	}
+	return s.NewGameStateView(gs, agent.GetID())
+}
+
+// whether the agent can read the notice board of the bike: its riders can, and so can the agents asking to join it
+// if utils.NoticesVisibleToApplicants
+func canReadNotices(gs GameStateDump, reader uuid.UUID, bikeID uuid.UUID) bool {
+	agent, ok := gs.Agents[reader]
+	if !ok || agent.BikeID != bikeID {
+		return false
+	}
+	return agent.OnBike || utils.NoticesVisibleToApplicants
+}
+
+// the game dump with only the notice boards the agent can read
+func withReadableNotices(gs GameStateDump, reader uuid.UUID) GameStateDump {
+	bikes := make(map[uuid.UUID]BikeDump, len(gs.Bikes))
+	for id, bike := range gs.Bikes {
+		if !canReadNotices(gs, reader, id) {
+			bike.Notices = nil
+		}
+		bikes[id] = bike
+	}
+	gs.Bikes = bikes
+	return gs
+}
+
+// builds the view of the game state of an agent
+func (s *Server) NewGameStateView(gs GameStateDump, observer uuid.UUID) GameStateView {
+	full := s.observability == utils.FullObservability
+	// the agent sees from the bike it rides, or is riding to
+	self, known := gs.Agents[observer]
+	bikeID := uuid.Nil
+	var position utils.Coordinates
+	if known {
+		if bike, ok := gs.Bikes[self.BikeID]; ok {
+			bikeID = bike.ID
+			position = bike.GetPosition()
+		}
+	}
+	riding := known && self.OnBike && bikeID != uuid.Nil
+	// the positions of the other bikes are sensed with noise. Everything is visited in ID order
+	// so that the noise is drawn in the same order every time
+	bikePositions := make(map[uuid.UUID]utils.Coordinates, len(gs.Bikes))
+	for _, id := range sortedKeys(gs.Bikes) {
+		if id == bikeID {
+			bikePositions[id] = position
+		} else {
+			bikePositions[id] = s.sensePosition(gs.Bikes[id].GetPosition())
+		}
+	}
+
+	// fellow riders are seen in full, the agents on other bikes within the vision radius in part
+	agents := make(map[uuid.UUID]AgentDump)
+	insiders := make(map[uuid.UUID]bool) // the agents whose transfers the observer knows of
+	for _, id := range sortedKeys(gs.Agents) {
+		agent := gs.Agents[id]
+		fellow := riding && agent.OnBike && agent.BikeID == bikeID
+		switch {
+		case id == observer, fellow, full:
+			agents[id] = s.fullAgentDump(agent)
+			insiders[id] = true
+		case bikeID != uuid.Nil && agent.OnBike && agent.BikeID != uuid.Nil &&
+			// ComputeDistance returns the square of the distance
+			physics.ComputeDistance(position, agent.Location) <= utils.VisionRadius*utils.VisionRadius:
+			agents[id] = s.partialAgentDump(agent)
+		case riding && !agent.OnBike && agent.BikeID == bikeID:
+			// agents asking to join the bike come up to it
+			agents[id] = s.partialAgentDump(agent)
+		default:
+			continue
+		}
+		if sensed, ok := bikePositions[agent.BikeID]; ok && agent.OnBike && !fellow && id != observer {
+			seen := agents[id]
+			seen.Location = sensed
+			agents[id] = seen
+		}
+	}
+
+	bikes := make(map[uuid.UUID]BikeDump, len(gs.Bikes))
+	for id, bike := range gs.Bikes {
+		bikeView := bike
+		bikeView.PhysicalState.Position = bikePositions[id]
+		bikeView.Agents = make([]AgentDump, 0, len(bike.Agents))
+		bikeView.AgentIDs = make([]uuid.UUID, 0, len(bike.AgentIDs))
+		for _, rider := range bike.Agents {
+			if seen, ok := agents[rider.ID]; ok {
+				bikeView.Agents = append(bikeView.Agents, seen)
+				bikeView.AgentIDs = append(bikeView.AgentIDs, seen.ID)
+			}
+		}
+		if !full && (id != bikeID || !riding) {
+			bikeView.VoteWeights = nil
+		}
+		if !canReadNotices(gs, observer, id) {
+			bikeView.Notices = nil
+		}
+		bikes[id] = bikeView
+	}
+
+	lootBoxes := make(map[uuid.UUID]LootBoxDump, len(gs.LootBoxes))
+	for _, id := range sortedKeys(gs.LootBoxes) {
+		lootBoxes[id] = s.senseLootBox(gs.LootBoxes[id])
+	}
+	audi := gs.Audi
+	audi.PhysicalState.Position = s.sensePosition(audi.GetPosition())
+
+	// the agent only knows of the transfers it or its fellow riders took part in
+	energyLedger := make([]objects.EnergyTransaction, 0)
+	for _, transaction := range gs.EnergyLedger {
+		if insiders[transaction.From] || insiders[transaction.To] {
+			energyLedger = append(energyLedger, transaction)
+		}
+	}
+	loans := make([]objects.EnergyLoan, 0)
+	for _, loan := range gs.Loans {
+		if insiders[loan.Lender] || insiders[loan.Borrower] {
+			loans = append(loans, loan)
+		}
+	}
+	pointsLedger := make([]objects.PointsTransaction, 0)
+	for _, transaction := range gs.PointsLedger {
+		if insiders[transaction.From] || insiders[transaction.To] {
+			pointsLedger = append(pointsLedger, transaction)
+		}
+	}
+
+	return GameStateView{
+		observer:     observer,
+		agents:       agents,
+		bikes:        bikes,
+		lootBoxes:    lootBoxes,
+		audi:         audi,
+		energyLedger: energyLedger,
+		loans:        loans,
+		pointsLedger: pointsLedger,
+	}
+}
+
+// what an agent can tell about itself, its fellow riders, or anyone under full observability: everything but
+// the forces, which it only knows utils.SensorModel.ForceDelay rounds late, if at all
+func (s *Server) fullAgentDump(agent AgentDump) AgentDump {
+	if s.sensors.ForceDelay > 0 {
+		agent.Forces = s.delayedForces(agent.ID)
+		agent.forcesObserved = true
+	} else {
+		agent.Forces = utils.Forces{}
+	}
+	return agent
+}
+
+// what an agent can tell about an agent on another bike: where it is, its colour and roughly its energy
+func (s *Server) partialAgentDump(agent AgentDump) AgentDump {
+	energy := agent.EnergyLevel + s.observationRand.NormFloat64()*utils.ObservedEnergyNoise
+	return AgentDump{
+		ID:           agent.ID,
+		Class:        agent.Class,
+		EnergyLevel:  math.Max(energy, 0.0),
+		Colour:       agent.Colour,
+		ColourString: agent.ColourString,
+		Location:     agent.Location,
+		OnBike:       agent.OnBike,
+		BikeID:       agent.BikeID,
+		GroupID:      agent.GroupID,
+	}
+}
+
+// adds gaussian noise to a sensed position
+func (s *Server) sensePosition(position utils.Coordinates) utils.Coordinates {
+	if s.sensors.PositionNoise <= 0 {
+		return position
+	}
+	return utils.Coordinates{
+		X: position.X + s.observationRand.NormFloat64()*s.sensors.PositionNoise,
+		Y: position.Y + s.observationRand.NormFloat64()*s.sensors.PositionNoise,
+	}
+}
+
+// reports the resources of a lootbox as a range that holds the real value somewhere within it.
+// The total resources seen are the middle of the range
+func (s *Server) senseLootBox(lootBox LootBoxDump) LootBoxDump {
+	if s.sensors.LootBoxRange <= 0 {
+		return lootBox
+	}
+	lootBox.MinResources = math.Max(lootBox.TotalResources-s.observationRand.Float64()*s.sensors.LootBoxRange, 0.0)
+	lootBox.MaxResources = lootBox.MinResources + s.sensors.LootBoxRange
+	lootBox.TotalResources = (lootBox.MinResources + lootBox.MaxResources) / 2
+	return lootBox
+}
+
+// keeps the forces of every agent once they have decided them, for the sensor model to report them late
+// and for audits to check claims about them
+func (s *Server) recordForces() {
+	forces := make(map[uuid.UUID]utils.Forces, len(s.GetAgentMap()))
+	for id, agent := range s.GetAgentMap() {
+		forces[id] = agent.GetForces()
+	}
+	s.appliedForces[s.round] = forces
+	s.forceHistory = append(s.forceHistory, forces)
+	if len(s.forceHistory) > s.sensors.ForceDelay+1 {
+		s.forceHistory = s.forceHistory[len(s.forceHistory)-s.sensors.ForceDelay-1:]
+	}
+}
+
+// the forces of an agent as they were utils.SensorModel.ForceDelay rounds ago (none before it first pedalled)
+func (s *Server) delayedForces(agentID uuid.UUID) utils.Forces {
+	if i := len(s.forceHistory) - 1 - s.sensors.ForceDelay; i >= 0 {
+		return s.forceHistory[i][agentID]
+	}
+	return utils.Forces{}
+}
+
+// returns the keys of the map sorted from the lowest to the highest UUID
+func sortedKeys[V any](m map[uuid.UUID]V) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// returns the ID of the agent the view belongs to
+func (v GameStateView) GetObserver() uuid.UUID {
+	return v.observer
+}
+
+func (v GameStateView) GetLootBoxes() map[uuid.UUID]objects.ILootBox {
+	result := make(map[uuid.UUID]objects.ILootBox, len(v.lootBoxes))
+	for id, lb := range v.lootBoxes {
+		result[id] = lb
+	}
+	return result
+}
+
+func (v GameStateView) GetMegaBikes() map[uuid.UUID]objects.IMegaBike {
+	result := make(map[uuid.UUID]objects.IMegaBike, len(v.bikes))
+	for id, mb := range v.bikes {
+		result[id] = mb
+	}
+	return result
+}
+
+func (v GameStateView) GetAgents() map[uuid.UUID]objects.IBaseBiker {
+	result := make(map[uuid.UUID]objects.IBaseBiker, len(v.agents))
+	for id, a := range v.agents {
+		result[id] = a
+	}
+	return result
+}
+
+func (v GameStateView) GetAudi() objects.IAudi {
+	return v.audi
+}
+
+func (v GameStateView) GetEnergyLedger() []objects.EnergyTransaction {
+	return slices.Clone(v.energyLedger)
+}
+
+func (v GameStateView) GetOutstandingLoans() []objects.EnergyLoan {
+	return slices.Clone(v.loans)
+}
+
+func (v GameStateView) GetPointsLedger() []objects.PointsTransaction {
+	return slices.Clone(v.pointsLedger)
+}
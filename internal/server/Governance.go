@@ -4,6 +4,7 @@ import (
 	"SOMAS2023/internal/common/objects"
 	"SOMAS2023/internal/common/utils"
 	"SOMAS2023/internal/common/voting"
+	"fmt"
 
 	"github.com/google/uuid"
 )
@@ -18,11 +19,18 @@ func (s *Server) RunRulerAction(bike objects.IMegaBike) uuid.UUID {
 }
 
 func (s *Server) RulerElection(agents []objects.IBaseBiker, governance utils.Governance) uuid.UUID {
+	return s.electRuler(agents, governance, nil, utils.VoteAction, s.tieBreaker(uuid.Nil, agents))
+}
+
+// runs an election among the agents with the given voting method. If candidates isn't nil, only the candidates can be elected
+func (s *Server) electRuler(agents []objects.IBaseBiker, governance utils.Governance, candidates []uuid.UUID, method utils.VoteMethod, tieBreaker *voting.TieBreaker) uuid.UUID {
 	// TODO: need extra input "voteWeight". For now, we just initialise a unit weight for each agent
-	votes := make(map[uuid.UUID]voting.IdVoteMap, len(agents))
+	votes := make(map[uuid.UUID]voting.IVoter, len(agents))
 	voteWeight := make(map[uuid.UUID]float64)
+	riderIDs := make([]uuid.UUID, 0, len(agents))
 	for _, agent := range agents {
 		voteWeight[agent.GetID()] = 1
+		riderIDs = append(riderIDs, agent.GetID())
 		switch governance {
 		case utils.Dictatorship:
 			votes[agent.GetID()] = agent.VoteDictator()
@@ -31,15 +39,48 @@ func (s *Server) RulerElection(agents []objects.IBaseBiker, governance utils.Gov
 		}
 	}
 
-	IVotes := make(map[uuid.UUID]voting.IVoter, len(votes))
-	for i, vote := range votes {
-		IVotes[i] = vote
+	s.recordBallots(objects.RulerVoteClaim, votes)
+
+	// only the riders can be voted for
+	bikeID := uuid.Nil
+	if len(agents) != 0 {
+		bikeID = agents[0].GetBike()
+	}
+	ballots := s.sanitiseBallots(bikeID, votes, func(id uuid.UUID) bool {
+		_, isRider := voteWeight[id]
+		return isRider
+	})
+	if candidates != nil {
+		for id, ballot := range ballots {
+			ballots[id] = restrictToCandidates(ballot.GetVotes(), candidates)
+		}
 	}
 
-	ruler := voting.WinnerFromDist(IVotes, voteWeight)
+	voteWeight = s.addPurchasedVoteWeight(voteWeight)
+
+	ruler, err := voting.WinnerFromDistWithMethod(ballots, voteWeight, method, tieBreaker)
+	if err != nil {
+		// nobody cast a valid vote, so the tie breaker picks among everyone that can be elected
+		if candidates == nil {
+			candidates = riderIDs
+		}
+		return tieBreaker.Break(candidates)
+	}
+	s.analyseVote(bikeID, utils.Election, method, ballots, voteWeight, tieBreaker, ruler)
 	return ruler
 }
 
+// returns the lootbox an agent proposes as direction in the current round. Agents are only asked once a round,
+// the negotiation session and the direction vote share their proposal
+func (s *Server) proposedDirection(agent objects.IBaseBiker) uuid.UUID {
+	if direction, ok := s.directionProposals[agent.GetID()]; ok {
+		return direction
+	}
+	direction := agent.ProposeDirection()
+	s.directionProposals[agent.GetID()] = direction
+	return direction
+}
+
 func (s *Server) RunDemocraticAction(bike objects.IMegaBike, weights map[uuid.UUID]float64) uuid.UUID {
 	// map of the proposed lootboxes by bike (for each bike a list of lootbox proposals is made, with one lootbox proposed by each agent on the bike)
 	agents := bike.GetAgents()
@@ -49,25 +90,56 @@ func (s *Server) RunDemocraticAction(bike objects.IMegaBike, weights map[uuid.UU
 		// will participate in the voting for the directions
 		// ---------------------------VOTING ROUTINE - STEP 1 ---------------------
 		if agent.GetBikeStatus() {
-			proposedDirection := agent.ProposeDirection()
+			proposedDirection := s.proposedDirection(agent)
 			if _, ok := s.lootBoxes[proposedDirection]; !ok {
-				panic("agent proposed a non-existent lootbox")
+				// the proposal is left out of the vote
+				s.penaliseAgent(bike.GetID(), agent.GetID(), fmt.Sprintf("proposed a non-existent lootbox %s", proposedDirection))
+				continue
 			}
 			proposedDirections[agent.GetID()] = proposedDirection
 		}
 	}
 
 	// pass the pitched directions of a bike to all agents on that bike and get their final vote
-	finalVotes := make(map[uuid.UUID]voting.LootboxVoteMap, len(agents))
+	finalVotes := make(map[uuid.UUID]voting.IVoter, len(agents))
 	for _, agent := range agents {
 		// ---------------------------VOTING ROUTINE - STEP 2 ---------------------
 		finalVotes[agent.GetID()] = agent.FinalDirectionVote(proposedDirections)
 	}
 
 	// ---------------------------VOTING ROUTINE - STEP 3 --------------
-	direction := s.GetWinningDirection(finalVotes, weights)
-	if _, ok := s.lootBoxes[direction]; !ok {
-		panic("agents voted on a non-existent lootbox")
+	s.recordBallots(objects.DirectionVoteClaim, finalVotes)
+	ballots := s.sanitiseBallots(bike.GetID(), finalVotes, func(id uuid.UUID) bool {
+		_, ok := s.lootBoxes[id]
+		return ok
+	})
+	tieBreaker := s.tieBreaker(s.lastDirections[bike.GetID()], nil)
+	direction, err := s.getWinningDirection(ballots, weights, bike.GetVoteMethod(utils.Direction), tieBreaker)
+	if err != nil {
+		// nobody cast a valid vote, so the bike keeps going
+		direction = s.fallbackDirection(bike)
+	} else {
+		s.analyseVote(bike.GetID(), utils.Direction, bike.GetVoteMethod(utils.Direction), ballots, weights, tieBreaker, direction)
 	}
+	s.lastDirections[bike.GetID()] = direction
 	return direction
 }
+
+// removes the votes for agents that aren't candidates and normalises the rest.
+// a vote for none of the candidates is spread equally between them
+func restrictToCandidates(vote voting.IdVoteMap, candidates []uuid.UUID) voting.IdVoteMap {
+	restricted := make(voting.IdVoteMap, len(candidates))
+	total := 0.0
+	for _, candidate := range candidates {
+		restricted[candidate] = vote[candidate]
+		total += vote[candidate]
+	}
+	for candidate := range restricted {
+		if total > 0.0 {
+			restricted[candidate] /= total
+		} else {
+			restricted[candidate] = 1.0 / float64(len(candidates))
+		}
+	}
+	return restricted
+}
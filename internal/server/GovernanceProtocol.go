@@ -0,0 +1,352 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// the way a bike with a given governance takes its decisions
+type IGovernanceProtocol interface {
+	Kickout(s *Server, bike objects.IMegaBike) []uuid.UUID                             // the riders to kick out
+	Joining(s *Server, bike objects.IMegaBike, pendingAgents []uuid.UUID) []uuid.UUID  // the agents accepted on the bike, in order of preference
+	Direction(s *Server, bike objects.IMegaBike) uuid.UUID                             // the lootbox the bike goes to
+	Allocation(s *Server, bike objects.IMegaBike) voting.IdVoteMap                     // how the loot of the bike is split between the riders
+	Succession(s *Server, bike objects.IMegaBike)                                      // appoints the ruler (or the council) of the bike
+	Amendment(s *Server, bike objects.IMegaBike, amendment objects.RuleAmendment) bool // whether the bike makes a change to its rules
+	ChangeThreshold() float64                                                          // the share of the vote a bike needs to switch to this governance
+	RecallThreshold() float64                                                          // the share of the riders that must vote to remove the rulers of a bike
+	InPower(bike objects.IMegaBike) ([]uuid.UUID, bool)                                // the riders in power, and whether the governance puts anyone in power
+	ElectionDue(s *Server, bike objects.IMegaBike) bool                                // whether the rulers of the bike have come to the end of their term
+	Election(s *Server, bike objects.IMegaBike)                                        // replaces the rulers of the bike at the end of their term
+}
+
+// the riders vote on every decision, each with the weight given by the vote weighting policy of the bike.
+// bikes whose governance has no protocol decide this way
+var democraticProtocol = VotingProtocol{
+	Weights:       democraticWeights,
+	VotePenalty:   utils.DeliberativeDemocracyPenalty,
+	Appoint:       noRuler,
+	Supermajority: utils.DemocracySupermajority,
+}
+
+// returns the protocols of the governances of the game, a server starts with these
+func defaultGovernanceProtocols() map[utils.Governance]IGovernanceProtocol {
+	return map[utils.Governance]IGovernanceProtocol{
+		utils.Democracy: democraticProtocol,
+		utils.Leadership: VotingProtocol{
+			Weights:       leaderWeights,
+			VotePenalty:   utils.LeadershipDemocracyPenalty,
+			Appoint:       appointElectedRuler,
+			Supermajority: utils.LeadershipSupermajority,
+			RecallShare:   utils.LeadershipRecallThreshold,
+			Rulers:        rulerOf,
+			TermLength:    utils.LeaderTermLength,
+			Elect: func(s *Server, bike objects.IMegaBike) {
+				s.RunLeadershipElection(bike)
+			},
+		},
+		utils.Dictatorship: DictatorshipProtocol{},
+		utils.RotatingLeadership: VotingProtocol{
+			Weights:     leaderWeights,
+			VotePenalty: utils.LeadershipDemocracyPenalty,
+			Appoint: func(s *Server, bike objects.IMegaBike) {
+				s.setRuler(bike, nextRotatingRuler(bike))
+			},
+			Supermajority: utils.RotatingLeadershipSupermajority,
+			RecallShare:   utils.LeadershipRecallThreshold,
+			Rulers:        rulerOf,
+			TermLength:    utils.RotationTermLength,
+		},
+		utils.Sortition: VotingProtocol{
+			Weights:     leaderWeights,
+			VotePenalty: utils.LeadershipDemocracyPenalty,
+			Appoint: func(s *Server, bike objects.IMegaBike) {
+				s.setRuler(bike, drawSortitionRuler(bike))
+			},
+			Supermajority: utils.SortitionSupermajority,
+			RecallShare:   utils.LeadershipRecallThreshold,
+			Rulers:        rulerOf,
+			TermLength:    utils.RotationTermLength,
+		},
+		utils.Council: VotingProtocol{
+			Weights:     councilWeights,
+			VotePenalty: utils.LeadershipDemocracyPenalty,
+			Appoint: func(s *Server, bike objects.IMegaBike) {
+				s.setRuler(bike, uuid.Nil)
+				bike.SetCouncil(s.electCouncil(bike, riderIDs(bike)))
+				s.councilElections[bike.GetID()] = s.round
+			},
+			Supermajority: utils.CouncilSupermajority,
+			RecallShare:   utils.LeadershipRecallThreshold,
+			Rulers:        councilOf,
+			TermLength:    utils.LeaderTermLength,
+		},
+	}
+}
+
+// sets the protocol the bikes with a governance take their decisions with, replacing the one it had
+func (s *Server) RegisterGovernanceProtocol(governance utils.Governance, protocol IGovernanceProtocol) {
+	s.governanceProtocols[governance] = protocol
+}
+
+// removes the protocol of a governance, the bikes with that governance then decide democratically
+func (s *Server) UnregisterGovernanceProtocol(governance utils.Governance) {
+	delete(s.governanceProtocols, governance)
+}
+
+// returns the protocol of a governance, and whether it has one
+func (s *Server) GetGovernanceProtocol(governance utils.Governance) (IGovernanceProtocol, bool) {
+	protocol, ok := s.governanceProtocols[governance]
+	return protocol, ok
+}
+
+// returns the protocol of the governance of a bike, or the democratic protocol if its governance has none
+func (s *Server) governanceProtocol(bike objects.IMegaBike) IGovernanceProtocol {
+	protocol, ok := s.GetGovernanceProtocol(bike.GetGovernance())
+	if !ok {
+		fmt.Printf("no protocol for governance %s, bike %s decides democratically \n", bike.GetGovernance(), bike.GetID())
+		return democraticProtocol
+	}
+	return protocol
+}
+
+// the ruler of a bike is in power, a bike without one has nobody in power
+func rulerOf(bike objects.IMegaBike) []uuid.UUID {
+	if bike.GetRuler() == uuid.Nil {
+		return []uuid.UUID{}
+	}
+	return []uuid.UUID{bike.GetRuler()}
+}
+
+// the council of a bike is in power
+func councilOf(bike objects.IMegaBike) []uuid.UUID {
+	return bike.GetCouncil()
+}
+
+// returns the round the rulers (or the council) of a bike took power in, and whether anyone is in power
+func (s *Server) termStart(bike objects.IMegaBike) (int, bool) {
+	if term, ok := s.rulerTerms[bike.GetID()]; ok {
+		return term.Start, true
+	}
+	elected, ok := s.councilElections[bike.GetID()]
+	return elected, ok
+}
+
+// the ruler decides the weight of the vote of each rider
+func leaderWeights(s *Server, bike objects.IMegaBike, action utils.Action) map[uuid.UUID]float64 {
+	leader := s.GetAgentMap()[bike.GetRuler()]
+	return leader.DecideWeights(action)
+}
+
+// only the council votes, with as much weight in total as all the riders
+func councilWeights(s *Server, bike objects.IMegaBike, action utils.Action) map[uuid.UUID]float64 {
+	weights := make(map[uuid.UUID]float64)
+	council := bike.GetCouncil()
+	for _, agent := range bike.GetAgents() {
+		weights[agent.GetID()] = 0.0
+	}
+	for _, member := range council {
+		weights[member] = float64(len(bike.GetAgents())) / float64(len(council))
+	}
+	return weights
+}
+
+func noRuler(s *Server, bike objects.IMegaBike) {
+	s.setRuler(bike, uuid.Nil)
+}
+
+func appointElectedRuler(s *Server, bike objects.IMegaBike) {
+	tieBreaker := s.tieBreaker(bike.GetRuler(), bike.GetAgents())
+	s.setRuler(bike, s.electRuler(bike.GetAgents(), bike.GetGovernance(), nil, bike.GetVoteMethod(utils.Election), tieBreaker))
+}
+
+// a governance where the riders vote on every decision, each with the weight given by Weights
+type VotingProtocol struct {
+	Weights       func(s *Server, bike objects.IMegaBike, action utils.Action) map[uuid.UUID]float64
+	VotePenalty   float64 // energy each rider loses for voting on the direction
+	Appoint       func(s *Server, bike objects.IMegaBike)
+	Supermajority float64                                  // share of the vote a bike needs to switch to this governance
+	RecallShare   float64                                  // share of the riders that must vote to remove the rulers
+	Rulers        func(bike objects.IMegaBike) []uuid.UUID // the riders in power, nil if the governance puts nobody in power
+	TermLength    int                                      // rounds between two elections, 0 if the bike holds none
+	Elect         func(s *Server, bike objects.IMegaBike)  // holds a scheduled election, nil if the rulers are appointed again
+}
+
+func (p VotingProtocol) weights(s *Server, bike objects.IMegaBike, action utils.Action) map[uuid.UUID]float64 {
+	return s.addPurchasedVoteWeight(s.sanitiseWeights(bike, p.Weights(s, bike, action)))
+}
+
+func (p VotingProtocol) Kickout(s *Server, bike objects.IMegaBike) []uuid.UUID {
+	// votes for agents that aren't on the bike are ignored
+	return s.sanitiseDecision(bike.GetID(), uuid.Nil, bike.KickOutAgent(p.weights(s, bike, utils.Kickout)), isRider(bike))
+}
+
+func (p VotingProtocol) Joining(s *Server, bike objects.IMegaBike, pendingAgents []uuid.UUID) []uuid.UUID {
+	weights := p.weights(s, bike, utils.Joining)
+	// get approval votes from each agent
+	responses := make(map[uuid.UUID](map[uuid.UUID]bool), len(bike.GetAgents())) // list containing all the agents' ranking
+	for _, agent := range bike.GetAgents() {
+		responses[agent.GetID()] = agent.DecideJoining(pendingAgents)
+	}
+	// accept agents based on the response outcome (it will have to be a ranking system, as only 8-n bikers can be accepted)
+	return s.sanitiseDecision(bike.GetID(), uuid.Nil, voting.GetAcceptanceRanking(responses, weights), isPending(pendingAgents))
+}
+
+func (p VotingProtocol) Direction(s *Server, bike objects.IMegaBike) uuid.UUID {
+	direction := s.RunDemocraticAction(bike, p.weights(s, bike, utils.Direction))
+	for _, agent := range bike.GetAgents() {
+		agent.UpdateEnergyLevel(-p.VotePenalty)
+	}
+	return direction
+}
+
+func (p VotingProtocol) Allocation(s *Server, bike objects.IMegaBike) voting.IdVoteMap {
+	agents := bike.GetAgents()
+	// weights given to agents that aren't on the bike are dropped by p.weights
+	weights := p.weights(s, bike, utils.Allocation)
+	// get allocation votes from each agent
+	allocations := make(map[uuid.UUID]voting.IVoter, len(agents))
+	for _, agent := range agents {
+		// the agents return their ideal lootbox split by assigning a number between 0 and 1 to
+		// each biker on their bike (including themselves)
+		allocations[agent.GetID()] = agent.DecideAllocation()
+	}
+	ballots := s.sanitiseBallots(bike.GetID(), allocations, isRider(bike))
+	allocation, err := voting.AllocationFromDist(ballots, weights, utils.AllocationAction)
+	if err != nil {
+		// nobody cast a valid vote, so the loot is split equally
+		allocation = equalAllocation(bike)
+	}
+	return allocation
+}
+
+// splits the loot of a bike equally between its riders
+func equalAllocation(bike objects.IMegaBike) voting.IdVoteMap {
+	allocation := make(voting.IdVoteMap, len(bike.GetAgents()))
+	for _, agent := range bike.GetAgents() {
+		allocation[agent.GetID()] = 1.0 / float64(len(bike.GetAgents()))
+	}
+	return allocation
+}
+
+// returns whether an agent is asking to join a bike
+func isPending(pendingAgents []uuid.UUID) func(uuid.UUID) bool {
+	return func(id uuid.UUID) bool {
+		return slices.Contains(pendingAgents, id)
+	}
+}
+
+func (p VotingProtocol) Succession(s *Server, bike objects.IMegaBike) {
+	p.Appoint(s, bike)
+}
+
+func (p VotingProtocol) Amendment(s *Server, bike objects.IMegaBike, amendment objects.RuleAmendment) bool {
+	weights := p.weights(s, bike, utils.RuleChange)
+	total, support := 0.0, 0.0
+	for _, agent := range bike.GetAgents() {
+		total += weights[agent.GetID()]
+		if agent.VoteRuleAmendment(amendment) {
+			support += weights[agent.GetID()]
+		}
+	}
+	return total > 0 && support/total >= utils.RuleAmendmentMajority-utils.Epsilon
+}
+
+func (p VotingProtocol) ChangeThreshold() float64 {
+	return p.Supermajority
+}
+
+func (p VotingProtocol) RecallThreshold() float64 {
+	return p.RecallShare
+}
+
+func (p VotingProtocol) InPower(bike objects.IMegaBike) ([]uuid.UUID, bool) {
+	if p.Rulers == nil {
+		return []uuid.UUID{}, false
+	}
+	return p.Rulers(bike), true
+}
+
+func (p VotingProtocol) ElectionDue(s *Server, bike objects.IMegaBike) bool {
+	if p.TermLength == 0 {
+		return false
+	}
+	start, ok := s.termStart(bike)
+	return !ok || s.round-start >= p.TermLength
+}
+
+func (p VotingProtocol) Election(s *Server, bike objects.IMegaBike) {
+	if p.Elect == nil {
+		s.appointRuler(bike)
+		return
+	}
+	p.Elect(s, bike)
+}
+
+// a governance where the ruler takes every decision
+type DictatorshipProtocol struct{}
+
+func (p DictatorshipProtocol) Kickout(s *Server, bike objects.IMegaBike) []uuid.UUID {
+	dictator := s.GetAgentMap()[bike.GetRuler()]
+	return s.sanitiseDecision(bike.GetID(), dictator.GetID(), dictator.DecideKickOut(), isRider(bike))
+}
+
+func (p DictatorshipProtocol) Joining(s *Server, bike objects.IMegaBike, pendingAgents []uuid.UUID) []uuid.UUID {
+	dictator := s.GetAgentMap()[bike.GetRuler()]
+	accepted := make([]uuid.UUID, 0)
+	for agentID, accept := range dictator.DecideJoining(pendingAgents) {
+		if accept {
+			accepted = append(accepted, agentID)
+		}
+	}
+	return s.sanitiseDecision(bike.GetID(), dictator.GetID(), accepted, isPending(pendingAgents))
+}
+
+func (p DictatorshipProtocol) Direction(s *Server, bike objects.IMegaBike) uuid.UUID {
+	return s.RunRulerAction(bike)
+}
+
+func (p DictatorshipProtocol) Allocation(s *Server, bike objects.IMegaBike) voting.IdVoteMap {
+	dictator := s.GetAgentMap()[bike.GetRuler()]
+	ballots := s.sanitiseBallots(bike.GetID(), map[uuid.UUID]voting.IVoter{dictator.GetID(): dictator.DecideDictatorAllocation()}, isRider(bike))
+	if ballot, ok := ballots[dictator.GetID()]; ok {
+		return ballot.GetVotes()
+	}
+	// the dictator gave no valid share to anyone, so the loot is split equally
+	return equalAllocation(bike)
+}
+
+func (p DictatorshipProtocol) Succession(s *Server, bike objects.IMegaBike) {
+	appointElectedRuler(s, bike)
+}
+
+func (p DictatorshipProtocol) Amendment(s *Server, bike objects.IMegaBike, amendment objects.RuleAmendment) bool {
+	dictator := s.GetAgentMap()[bike.GetRuler()]
+	return dictator.VoteRuleAmendment(amendment)
+}
+
+func (p DictatorshipProtocol) ChangeThreshold() float64 {
+	return utils.DictatorshipSupermajority
+}
+
+func (p DictatorshipProtocol) RecallThreshold() float64 {
+	return utils.DictatorshipRecallThreshold
+}
+
+func (p DictatorshipProtocol) InPower(bike objects.IMegaBike) ([]uuid.UUID, bool) {
+	return rulerOf(bike), true
+}
+
+// a dictator rules until they are overthrown or leave the bike
+func (p DictatorshipProtocol) ElectionDue(s *Server, bike objects.IMegaBike) bool {
+	return false
+}
+
+func (p DictatorshipProtocol) Election(s *Server, bike objects.IMegaBike) {
+	s.appointRuler(bike)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+	"github.com/google/uuid"
+)
+
+const payloadSummaryLength = 200 // payload summaries in the message log are cut to this many characters
+
+// a message delivered to one of its recipients, as it is written to the message log
+type MessageRecord struct {
+	Sender    uuid.UUID            `json:"sender"`
+	Recipient uuid.UUID            `json:"recipient"`
+	Type      string               `json:"type"`
+	Payload   string               `json:"payload"`
+	SentRound int                  `json:"sent_round"`
+	SentPhase utils.MessagingPhase `json:"sent_phase"`
+	Round     int                  `json:"round"`
+	Phase     utils.MessagingPhase `json:"phase"`
+	// whether the claim the message makes is true, checked against the records of the server once the round it was
+	// sent in is over
+	Honesty objects.AuditOutcome  `json:"honesty"`
+	claim   *objects.AuditRequest // the claim the message makes, nil if audits can't check it
+}
+
+// returns every message delivered in the current game, in the order they were delivered
+func (s *Server) GetMessageLog() []MessageRecord {
+	return slices.Clone(s.messageLog)
+}
+
+// returns the messages delivered in the current round
+func (s *Server) getRoundMessages() []MessageRecord {
+	messages := make([]MessageRecord, 0)
+	for _, record := range s.messageLog {
+		if record.Round == s.round {
+			messages = append(messages, record)
+		}
+	}
+	return messages
+}
+
+// logs the delivery of a message. Its claim is checked straight away if the round it is about is over
+func (s *Server) recordMessage(delivered objects.InboxMessage, recipient uuid.UUID, claim *objects.AuditRequest) {
+	record := MessageRecord{
+		Sender:    delivered.Sender,
+		Recipient: recipient,
+		Type:      messageType(delivered.Message),
+		Payload:   payloadSummary(delivered.Message),
+		SentRound: delivered.SentRound,
+		SentPhase: delivered.SentPhase,
+		Round:     delivered.Round,
+		Phase:     delivered.Phase,
+		Honesty:   objects.ClaimUnverifiable,
+		claim:     claim,
+	}
+	if claim != nil && claim.Round <= s.settledRound {
+		record.Honesty = s.Audit(*claim)
+	}
+	s.messageLog = append(s.messageLog, record)
+}
+
+// every event of the current round has happened: checks the claims about it made in the messages delivered so far
+func (s *Server) settleMessageClaims() {
+	s.settledRound = s.round
+	for i, record := range s.messageLog {
+		if record.claim != nil && record.claim.Round == s.round {
+			s.messageLog[i].Honesty = s.Audit(*record.claim)
+		}
+	}
+}
+
+// returns the claim a message makes about the current round, for the kinds of message audits can check
+func (s *Server) messageClaim(msg messaging.IMessage[objects.IBaseBiker]) *objects.AuditRequest {
+	var claim objects.AuditRequest
+	switch message := msg.(type) {
+	case objects.ForcesMessage:
+		claim = objects.AuditForcesMessage(message, s.round)
+	case objects.VoteLootboxDirectionMessage:
+		claim = objects.AuditVoteLootboxDirectionMessage(message, s.round)
+	case objects.VoteRulerMessage:
+		claim = objects.AuditVoteRulerMessage(message, s.round)
+	default:
+		return nil
+	}
+	return &claim
+}
+
+// the name of the type of the message, or the type a generic message declares
+func messageType(msg messaging.IMessage[objects.IBaseBiker]) string {
+	if generic, ok := msg.(objects.GenericMessage); ok {
+		return string(generic.Type)
+	}
+	return reflect.TypeOf(msg).Name()
+}
+
+// the fields of the message other than its sender and recipients, or the payload of a generic message
+func payloadSummary(msg messaging.IMessage[objects.IBaseBiker]) string {
+	var summary string
+	if generic, ok := msg.(objects.GenericMessage); ok {
+		summary = fmt.Sprintf("%+v", generic.Payload)
+	} else if value := reflect.ValueOf(msg); value.Kind() == reflect.Struct {
+		fields := make([]string, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Anonymous || !field.IsExported() {
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%s: %+v", field.Name, value.Field(i).Interface()))
+		}
+		summary = strings.Join(fields, ", ")
+	}
+	if len(summary) > payloadSummaryLength {
+		summary = summary[:payloadSummaryLength]
+	}
+	return summary
+}
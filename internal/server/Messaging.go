@@ -0,0 +1,208 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/physics"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+	"slices"
+
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+	"github.com/google/uuid"
+)
+
+// a message on its way to one of its recipients
+type pendingMessage struct {
+	message   objects.InboxMessage
+	recipient objects.IBaseBiker
+	due       int                   // the messaging session it is delivered in
+	claim     *objects.AuditRequest // the claim it makes, nil if audits can't check it
+}
+
+// runs the messaging session at the end of the round
+func (s *Server) RunMessagingSession() {
+	s.RunMessagingPhase(utils.EndOfRound)
+}
+
+// lets the agents message each other in the given phase of the round, if the server runs it. The messages
+// sent in earlier phases that are due are delivered before the agents write theirs
+func (s *Server) RunMessagingPhase(phase utils.MessagingPhase) {
+	if phase == utils.EndOfRound {
+		s.settleMessageClaims()
+	}
+	if !slices.Contains(s.messagingPhases, phase) {
+		return
+	}
+	s.messagingSession++
+	for _, agent := range s.GetAgentMap() {
+		agent.SetMessagingPhase(phase)
+	}
+	s.deliverMessages(phase)
+
+	agentArray := s.GenerateAgentArrayFromMap()
+	// senders are visited in ID order so that the lost messages are drawn in the same order every time
+	for _, senderID := range sortedKeys(s.GetAgentMap()) {
+		agent := s.GetAgentMap()[senderID]
+		for _, msg := range agent.GetAllMessages(agentArray) {
+			s.sendMessage(agent, msg, phase)
+		}
+	}
+	s.deliverMessages(phase)
+}
+
+// charges the sender for a message and puts it on its way to each of its recipients
+func (s *Server) sendMessage(agent objects.IBaseBiker, msg messaging.IMessage[objects.IBaseBiker], phase utils.MessagingPhase) {
+	recipients := msg.GetRecipients()
+	if !s.chargeMessage(agent, len(recipients)) {
+		return
+	}
+	claim := s.messageClaim(msg)
+	for _, recipient := range recipients {
+		if recipient == nil {
+			s.dropMessage(agent.GetID(), uuid.Nil, msg, objects.UnknownRecipient)
+			continue
+		}
+		if agent.GetID() == recipient.GetID() {
+			continue
+		}
+		// agents only have access to the game dump version of other agents, which
+		// can't call the handler functions, so messages go to the actual agents
+		recip, alive := s.GetAgentMap()[recipient.GetID()]
+		if !alive {
+			s.dropMessage(agent.GetID(), recipient.GetID(), msg, objects.UnknownRecipient)
+			continue
+		}
+		if !s.inMessageRange(agent, recip) {
+			s.dropMessage(agent.GetID(), recip.GetID(), msg, objects.RecipientOutOfRange)
+			continue
+		}
+		// the channel loses messages without the sender knowing
+		if s.messageLost() {
+			s.droppedMessages[agent.GetID()]++
+			continue
+		}
+		s.pendingMessages = append(s.pendingMessages, pendingMessage{
+			message: objects.InboxMessage{
+				Message:   msg,
+				Sender:    agent.GetID(),
+				SentRound: s.round,
+				SentPhase: phase,
+			},
+			recipient: recip,
+			due:       s.messagingSession + s.messageChannel.Delay,
+			claim:     claim,
+		})
+	}
+}
+
+// delivers the messages that are due, in the order they were sent. Those whose recipient died on the way are dropped
+func (s *Server) deliverMessages(phase utils.MessagingPhase) {
+	pending := make([]pendingMessage, 0, len(s.pendingMessages))
+	due := make([]pendingMessage, 0)
+	for _, message := range s.pendingMessages {
+		if message.due <= s.messagingSession {
+			due = append(due, message)
+		} else {
+			pending = append(pending, message)
+		}
+	}
+	s.pendingMessages = pending
+
+	for _, message := range due {
+		if _, alive := s.GetAgentMap()[message.recipient.GetID()]; !alive {
+			s.dropMessage(message.message.Sender, message.recipient.GetID(), message.message.Message, objects.RecipientDied)
+			continue
+		}
+		delivered := message.message
+		delivered.Round = s.round
+		delivered.Phase = phase
+		message.recipient.ReceiveMessage(delivered)
+		delivered.Message.InvokeMessageHandler(message.recipient)
+		s.recordMessage(delivered, message.recipient.GetID(), message.claim)
+	}
+}
+
+// drops the delivery of a message to a recipient, and tells the sender if it is still alive
+func (s *Server) dropMessage(senderID uuid.UUID, recipientID uuid.UUID, msg messaging.IMessage[objects.IBaseBiker], failure objects.DeliveryFailure) {
+	fmt.Printf("Message from agent %s to agent %s dropped: %s \n", senderID, recipientID, failure)
+	s.droppedMessages[senderID]++
+	sender, alive := s.GetAgentMap()[senderID]
+	if !alive {
+		return
+	}
+	receipt := objects.DeliveryReceiptMessage{
+		BaseMessage: messaging.CreateMessage[objects.IBaseBiker](sender, []objects.IBaseBiker{sender}),
+		Recipient:   recipientID,
+		Message:     msg,
+		Failure:     failure,
+	}
+	receipt.InvokeMessageHandler(sender)
+}
+
+// whether the channel loses a delivery
+func (s *Server) messageLost() bool {
+	return s.messageChannel.DropRate > 0 && s.messagingRand.Float64() < s.messageChannel.DropRate
+}
+
+// sets the phases of the round in which agents message each other
+func (s *Server) SetMessagingPhases(phases ...utils.MessagingPhase) {
+	s.messagingPhases = phases
+}
+
+// sets how late messages arrive and how often they are lost
+func (s *Server) SetMessageChannel(channel utils.MessageChannel) {
+	s.messageChannel = channel
+}
+
+// sets the limits on the messages agents send each other
+func (s *Server) SetMessagingLimits(limits utils.MessagingLimits) {
+	s.messagingLimits = limits
+}
+
+// charges the sender of a message with the given number of recipients. Returns false, and counts every recipient
+// as a dropped delivery, if the sender has used up its quota, can't afford the message or addressed too many agents
+func (s *Server) chargeMessage(sender objects.IBaseBiker, recipients int) bool {
+	limits := s.messagingLimits
+	cost := limits.MessageCost + limits.RecipientCost*float64(recipients)
+	var reason string
+	switch {
+	case limits.Quota > 0 && s.messagesSent[sender.GetID()] >= limits.Quota:
+		reason = "has used up its quota"
+	case limits.MaxRecipients > 0 && recipients > limits.MaxRecipients:
+		reason = fmt.Sprintf("addressed %d agents", recipients)
+	case cost > 0 && sender.GetEnergyLevel() < cost:
+		reason = "can't afford the message"
+	default:
+		s.messagesSent[sender.GetID()]++
+		if cost > 0 {
+			sender.UpdateEnergyLevel(-cost)
+		}
+		return true
+	}
+	fmt.Printf("Agent %s %s, the message is dropped \n", sender.GetID(), reason)
+	s.droppedMessages[sender.GetID()] += recipients
+	return false
+}
+
+// whether the recipient is close enough to the sender to get its messages. Agents that aren't on a bike
+// are where the bike they are heading to is
+func (s *Server) inMessageRange(sender objects.IBaseBiker, recipient objects.IBaseBiker) bool {
+	if s.messagingLimits.Range <= 0 {
+		return true
+	}
+	senderBike, ok := s.megaBikes[sender.GetBike()]
+	if !ok {
+		return false
+	}
+	recipientBike, ok := s.megaBikes[recipient.GetBike()]
+	if !ok {
+		return false
+	}
+	// ComputeDistance returns the square of the distance
+	return physics.ComputeDistance(senderBike.GetPosition(), recipientBike.GetPosition()) <= s.messagingLimits.Range*s.messagingLimits.Range
+}
+
+// the number of deliveries of the agent's messages dropped by the server in the current game
+func (s *Server) GetDroppedMessages(agentID uuid.UUID) int {
+	return s.droppedMessages[agentID]
+}
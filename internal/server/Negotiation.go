@@ -0,0 +1,220 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// NegotiationAgreement is a binding agreement between two bikes on how to share a lootbox if they both reach it
+type NegotiationAgreement struct {
+	LootboxID     uuid.UUID                    `json:"lootbox_id"`
+	ProposerBike  uuid.UUID                    `json:"proposer_bike"`
+	ResponderBike uuid.UUID                    `json:"responder_bike"`
+	OfferType     objects.NegotiationOfferType `json:"offer_type"`
+	ProposerShare float64                      `json:"proposer_share"` // fraction of the two bikes' loot going to the proposer
+	Payer         uuid.UUID                    `json:"payer"`          // negotiator that pays the side payment (if any)
+	Payment       float64                      `json:"payment"`
+}
+
+// identifies a lootbox contested by a pair of bikes, regardless of which one made the offer
+type negotiationKey struct {
+	lootbox uuid.UUID
+	bikeA   uuid.UUID
+	bikeB   uuid.UUID
+}
+
+func newNegotiationKey(lootbox uuid.UUID, bike1 uuid.UUID, bike2 uuid.UUID) negotiationKey {
+	if bike1.String() > bike2.String() {
+		bike1, bike2 = bike2, bike1
+	}
+	return negotiationKey{lootbox: lootbox, bikeA: bike1, bikeB: bike2}
+}
+
+func (s *Server) GetNegotiationAgreements() map[uuid.UUID][]NegotiationAgreement {
+	return s.negotiationAgreements
+}
+
+// the negotiator of a bike is its ruler if it has one, otherwise the rider with the most energy
+// (as it is the one best placed to make side payments)
+func (s *Server) GetNegotiator(bike objects.IMegaBike) objects.IBaseBiker {
+	agents := bike.GetAgents()
+	if len(agents) == 0 {
+		return nil
+	}
+	if ruler, ok := s.GetAgentMap()[bike.GetRuler()]; ok && ruler.GetBike() == bike.GetID() {
+		return ruler
+	}
+	negotiator := agents[0]
+	for _, agent := range agents[1:] {
+		if agent.GetEnergyLevel() > negotiator.GetEnergyLevel() {
+			negotiator = agent
+		}
+	}
+	return negotiator
+}
+
+// returns a map of lootboxes that more than one bike is aiming for to the bikes aiming for them
+// a bike is aiming for the lootbox its negotiator proposes as direction this round
+func (s *Server) GetContestedLootBoxes() map[uuid.UUID][]uuid.UUID {
+	targets := make(map[uuid.UUID][]uuid.UUID)
+	// bikes are visited in ID order so that the bikes contesting a lootbox are always listed in the same order
+	for _, bikeID := range sortedKeys(s.GetMegaBikes()) {
+		bike := s.megaBikes[bikeID]
+		negotiator := s.GetNegotiator(bike)
+		if negotiator == nil {
+			continue
+		}
+		target := s.proposedDirection(negotiator)
+		if _, ok := s.lootBoxes[target]; ok {
+			targets[target] = append(targets[target], bikeID)
+		}
+	}
+
+	contested := make(map[uuid.UUID][]uuid.UUID)
+	for lootboxID, bikes := range targets {
+		if len(bikes) > 1 {
+			contested[lootboxID] = bikes
+		}
+	}
+	return contested
+}
+
+// checks that an offer concerns a contested lootbox, is made by (and to) a bike contesting it and has sensible terms
+func (s *Server) isValidNegotiationOffer(offer objects.NegotiationOfferMessage, negotiator objects.IBaseBiker, contested map[uuid.UUID][]uuid.UUID) bool {
+	bikes, ok := contested[offer.LootboxId]
+	if !ok || offer.FromBikeId != negotiator.GetBike() || offer.FromBikeId == offer.ToBikeId {
+		return false
+	}
+	fromFound, toFound := false, false
+	for _, bikeID := range bikes {
+		fromFound = fromFound || bikeID == offer.FromBikeId
+		toFound = toFound || bikeID == offer.ToBikeId
+	}
+	if !fromFound || !toFound {
+		return false
+	}
+	switch offer.OfferType {
+	case objects.Claim, objects.Yield:
+		return true
+	case objects.SplitProposal:
+		return offer.Share >= 0.0 && offer.Share <= 1.0
+	case objects.SidePayment:
+		return offer.Payment > 0.0 && offer.Payment <= negotiator.GetEnergyLevel()
+	default:
+		return false
+	}
+}
+
+func newNegotiationAgreement(offer objects.NegotiationOfferMessage, negotiator objects.IBaseBiker) NegotiationAgreement {
+	agreement := NegotiationAgreement{
+		LootboxID:     offer.LootboxId,
+		ProposerBike:  offer.FromBikeId,
+		ResponderBike: offer.ToBikeId,
+		OfferType:     offer.OfferType,
+	}
+	switch offer.OfferType {
+	case objects.Claim:
+		agreement.ProposerShare = 1.0
+	case objects.Yield:
+		agreement.ProposerShare = 0.0
+	case objects.SplitProposal:
+		agreement.ProposerShare = offer.Share
+	case objects.SidePayment:
+		agreement.ProposerShare = 1.0
+		agreement.Payer = negotiator.GetID()
+		agreement.Payment = offer.Payment
+	}
+	return agreement
+}
+
+// runs a bounded number of negotiation rounds between the negotiators of bikes aiming for the same lootbox.
+// every accepted offer becomes a binding agreement, enforced if the lootbox is distributed this round.
+// agreements expire at the next session, so bikes still contesting a lootbox negotiate again
+func (s *Server) RunNegotiationSession() {
+	clear(s.negotiationAgreements)
+	contested := s.GetContestedLootBoxes()
+	if len(contested) == 0 {
+		return
+	}
+
+	negotiators := make(map[uuid.UUID]objects.IBaseBiker)
+	for _, bikes := range contested {
+		for _, bikeID := range bikes {
+			negotiators[bikeID] = s.GetNegotiator(s.megaBikes[bikeID])
+		}
+	}
+
+	resolved := make(map[negotiationKey]bool)
+
+	for round := 0; round < utils.NegotiationRounds; round++ {
+		// negotiators make their offers in the ID order of their bikes, so that the same offers reach the same agreements
+		for _, bikeID := range sortedKeys(negotiators) {
+			negotiator := negotiators[bikeID]
+			for _, offer := range negotiator.DecideNegotiationOffers(contested) {
+				if !s.isValidNegotiationOffer(offer, negotiator, contested) {
+					continue
+				}
+				key := newNegotiationKey(offer.LootboxId, offer.FromBikeId, offer.ToBikeId)
+				if resolved[key] {
+					continue
+				}
+				// yielding doesn't need the consent of the other bike
+				if offer.OfferType == objects.Yield || negotiators[offer.ToBikeId].RespondToNegotiationOffer(offer) {
+					fmt.Printf("Bikes %s and %s reached an agreement on lootbox %s \n", offer.FromBikeId, offer.ToBikeId, offer.LootboxId)
+					s.negotiationAgreements[offer.LootboxId] = append(s.negotiationAgreements[offer.LootboxId], newNegotiationAgreement(offer, negotiator))
+					resolved[key] = true
+				}
+			}
+		}
+	}
+}
+
+// returns the fraction of a lootbox received by each of the bikes that collided with it. The lootbox is split
+// equally, unless a pair of colliding bikes has agreed on a different split
+func (s *Server) lootShares(lootboxID uuid.UUID, bikes []uuid.UUID) map[uuid.UUID]float64 {
+	shares := make(map[uuid.UUID]float64, len(bikes))
+	for _, bikeID := range bikes {
+		shares[bikeID] = 1.0 / float64(len(bikes))
+	}
+	for _, agreement := range s.negotiationAgreements[lootboxID] {
+		proposerShare, proposerLooted := shares[agreement.ProposerBike]
+		responderShare, responderLooted := shares[agreement.ResponderBike]
+		if proposerLooted && responderLooted {
+			pool := proposerShare + responderShare
+			shares[agreement.ProposerBike] = pool * agreement.ProposerShare
+			shares[agreement.ResponderBike] = pool * (1.0 - agreement.ProposerShare)
+		}
+	}
+	return shares
+}
+
+// transfers the side payments agreed on for a lootbox the paying bike has looted and discards its agreements
+func (s *Server) settleNegotiationAgreements(lootboxID uuid.UUID, bikes []uuid.UUID) {
+	for _, agreement := range s.negotiationAgreements[lootboxID] {
+		if agreement.OfferType != objects.SidePayment {
+			continue
+		}
+		payer, alive := s.GetAgentMap()[agreement.Payer]
+		bike, exists := s.megaBikes[agreement.ResponderBike]
+		if !alive || !exists || len(bike.GetAgents()) == 0 {
+			continue
+		}
+		paid := false
+		for _, bikeID := range bikes {
+			paid = paid || bikeID == agreement.ProposerBike
+		}
+		if !paid {
+			continue
+		}
+		payment := min(agreement.Payment, max(payer.GetEnergyLevel(), 0.0))
+		payer.UpdateEnergyLevel(-payment)
+		for _, agent := range bike.GetAgents() {
+			agent.UpdateEnergyLevel(payment / float64(len(bike.GetAgents())))
+		}
+		fmt.Printf("Agent %s paid %f energy to bike %s \n", payer.GetID(), payment, bike.GetID())
+	}
+	delete(s.negotiationAgreements, lootboxID)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// lets the riders of every bike change its notice board, as far as the governance of the bike allows them to
+func (s *Server) RunNoticeBoards() {
+	for _, bike := range s.GetMegaBikes() {
+		for _, rider := range bike.GetAgents() {
+			for _, post := range rider.DecideNoticeBoardPosts() {
+				s.changeNoticeBoard(bike, rider.GetID(), post)
+			}
+		}
+	}
+}
+
+// applies the change a rider wants to make to the notice board of its bike. Returns false if the rider isn't allowed to
+// make it, or the board has no room for it
+func (s *Server) changeNoticeBoard(bike objects.IMegaBike, riderID uuid.UUID, post objects.NoticeBoardPost) bool {
+	notices := bike.GetNotices()
+	i := slices.IndexFunc(notices, func(notice objects.Notice) bool { return notice.ID == post.NoticeID })
+	var reason string
+	switch {
+	case post.Action != objects.PostNotice && i < 0:
+		reason = "there is no such notice"
+	case post.Action != objects.PostNotice && !mayChangeNotice(bike, riderID, notices[i]):
+		reason = "it can't change the notice"
+	case post.Action != objects.RemoveNotice && !mayPostNotice(bike, riderID, post.Kind):
+		reason = fmt.Sprintf("it can't post a %s", post.Kind)
+	case post.Action != objects.RemoveNotice && len(post.Text) > utils.NoticeLength:
+		reason = "the notice is too long"
+	case post.Action == objects.PostNotice && len(notices) >= utils.NoticeBoardSize:
+		reason = "the notice board is full"
+	}
+	if reason != "" {
+		fmt.Printf("Agent %s can't change the notice board of bike %s: %s \n", riderID, bike.GetID(), reason)
+		return false
+	}
+
+	switch post.Action {
+	case objects.PostNotice:
+		notices = append(notices, objects.Notice{
+			ID:          uuid.New(),
+			Author:      riderID,
+			Kind:        post.Kind,
+			Text:        post.Text,
+			Target:      post.Target,
+			Round:       s.round,
+			Editor:      riderID,
+			EditedRound: s.round,
+		})
+	case objects.EditNotice:
+		notices[i].Kind = post.Kind
+		notices[i].Text = post.Text
+		notices[i].Target = post.Target
+		notices[i].Editor = riderID
+		notices[i].EditedRound = s.round
+	case objects.RemoveNotice:
+		notices = slices.Delete(notices, i, i+1)
+	}
+	bike.SetNotices(notices)
+	return true
+}
+
+// the riders that can post any kind of notice, and change any notice: the ruler, or the council of a council.
+// A democracy has none
+func noticeBoardModerators(bike objects.IMegaBike) []uuid.UUID {
+	switch bike.GetGovernance() {
+	case utils.Democracy:
+		return []uuid.UUID{}
+	case utils.Council:
+		return bike.GetCouncil()
+	default:
+		return []uuid.UUID{bike.GetRuler()}
+	}
+}
+
+// under a dictatorship only the ruler posts. Elsewhere plans and rules are left to the moderators,
+// unless the bike is a democracy
+func mayPostNotice(bike objects.IMegaBike, riderID uuid.UUID, kind objects.NoticeKind) bool {
+	switch {
+	case slices.Contains(noticeBoardModerators(bike), riderID):
+		return true
+	case bike.GetGovernance() == utils.Dictatorship:
+		return false
+	case bike.GetGovernance() == utils.Democracy:
+		return true
+	default:
+		return kind == objects.ProposalNotice || kind == objects.CommitmentNotice
+	}
+}
+
+// the moderators can change any notice, the other riders only their own unless the bike is a dictatorship
+func mayChangeNotice(bike objects.IMegaBike, riderID uuid.UUID, notice objects.Notice) bool {
+	if slices.Contains(noticeBoardModerators(bike), riderID) {
+		return true
+	}
+	return notice.Author == riderID && bike.GetGovernance() != utils.Dictatorship
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) GetPointsLedger() []objects.PointsTransaction {
+	return slices.Clone(s.pointsLedger)
+}
+
+func (s *Server) recordPointsTransaction(purchaseType objects.PointsPurchaseType, from uuid.UUID, to uuid.UUID, points int) {
+	s.pointsLedger = append(s.pointsLedger, objects.PointsTransaction{
+		Round:  s.round,
+		Type:   purchaseType,
+		From:   from,
+		To:     to,
+		Points: points,
+	})
+}
+
+// returns a copy of the weights of a vote with the vote weight bought with points this round added on top
+func (s *Server) addPurchasedVoteWeight(weights map[uuid.UUID]float64) map[uuid.UUID]float64 {
+	result := maps.Clone(weights)
+	for agentID := range result {
+		result[agentID] += s.purchasedVoteWeight[agentID]
+	}
+	return result
+}
+
+// validates and carries out a purchase. Returns false if the purchase isn't allowed
+func (s *Server) processPointsPurchase(agent objects.IBaseBiker, purchase objects.PointsPurchase) bool {
+	switch purchase.Type {
+	case objects.VoteWeightPurchase:
+		if !agent.GetBikeStatus() || purchase.Points <= 0 || purchase.Points > agent.GetPoints() {
+			return false
+		}
+		agent.UpdatePoints(-purchase.Points)
+		s.purchasedVoteWeight[agent.GetID()] += float64(purchase.Points) * utils.VoteWeightPerPoint
+		s.recordPointsTransaction(purchase.Type, agent.GetID(), uuid.Nil, purchase.Points)
+
+	case objects.BribePurchase:
+		bike, onBike := s.megaBikes[agent.GetBike()]
+		if !agent.GetBikeStatus() || !onBike || purchase.Points <= 0 || purchase.Points > agent.GetPoints() {
+			return false
+		}
+		ruler, ok := s.GetAgentMap()[bike.GetRuler()]
+		if !ok || ruler.GetID() == agent.GetID() {
+			return false
+		}
+		agent.UpdatePoints(-purchase.Points)
+		ruler.UpdatePoints(purchase.Points)
+		s.recordPointsTransaction(purchase.Type, agent.GetID(), ruler.GetID(), purchase.Points)
+
+	case objects.BikeEntryPurchase:
+		// the fee is only paid if the agent is let on the bike
+		if _, exists := s.megaBikes[agent.GetBike()]; agent.GetBikeStatus() || !exists || agent.GetPoints() < utils.BikeEntryCost {
+			return false
+		}
+		s.paidEntries[agent.GetID()] = agent.GetBike()
+
+	case objects.ColourChangePurchase:
+		if purchase.Colour < 0 || purchase.Colour >= utils.NumOfColours || purchase.Colour == agent.GetColour() || agent.GetPoints() < utils.ColourChangeCost {
+			return false
+		}
+		agent.UpdatePoints(-utils.ColourChangeCost)
+		s.setAgentColour(agent, purchase.Colour)
+		s.recordPointsTransaction(purchase.Type, agent.GetID(), uuid.Nil, utils.ColourChangeCost)
+
+	default:
+		return false
+	}
+	return true
+}
+
+// lets agents spend their points. Vote weight lasts for the current round only
+func (s *Server) RunPointsPurchases() {
+	clear(s.purchasedVoteWeight)
+	clear(s.paidEntries)
+	for agentID, agent := range s.GetAgentMap() {
+		for _, purchase := range agent.DecidePointsPurchases() {
+			if !s.processPointsPurchase(agent, purchase) {
+				fmt.Printf("Agent %s made an invalid purchase \n", agentID)
+			}
+		}
+	}
+}
+
+// lets the agents who paid to join a bike onto it without a vote (as long as there is a paid seat left) and
+// returns the agents that still have to go through the acceptance process. The fee is shared between the riders
+func (s *Server) admitPaidEntrants(bike objects.IMegaBike, pendingAgents []uuid.UUID) []uuid.UUID {
+	remaining := make([]uuid.UUID, 0, len(pendingAgents))
+	for _, agentID := range pendingAgents {
+		agent := s.GetAgentMap()[agentID]
+		riders := bike.GetAgents()
+		if s.paidEntries[agentID] != bike.GetID() || len(riders) >= utils.BikersOnBike+utils.MaxPaidSeats || agent.GetPoints() < utils.BikeEntryCost {
+			remaining = append(remaining, agentID)
+			continue
+		}
+		agent.UpdatePoints(-utils.BikeEntryCost)
+		share := utils.BikeEntryCost / len(riders)
+		for _, rider := range riders {
+			rider.UpdatePoints(share)
+			s.recordPointsTransaction(objects.BikeEntryPurchase, agentID, rider.GetID(), share)
+		}
+		if burnt := utils.BikeEntryCost - share*len(riders); burnt > 0 {
+			s.recordPointsTransaction(objects.BikeEntryPurchase, agentID, uuid.Nil, burnt)
+		}
+		s.AddAgentToBike(agent)
+		delete(s.paidEntries, agentID)
+		fmt.Printf("Agent %s paid to join bike %s \n", agentID, bike.GetID())
+	}
+	return remaining
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// collects the petitions for a vote of no confidence in the rulers of a bike. Petitioners pay for it whether or not the
+// quorum is reached. Returns true if enough riders petitioned for the vote to be held
+func (s *Server) collectRecallPetitions(bike objects.IMegaBike, rulers []uuid.UUID) bool {
+	agents := bike.GetAgents()
+	petitioners := 0
+	for _, agent := range agents {
+		if !slices.Contains(rulers, agent.GetID()) && agent.DecideRecall() {
+			agent.UpdateEnergyLevel(-utils.RecallPetitionCost)
+			petitioners++
+		}
+	}
+	return petitioners > 0 && float64(petitioners)/float64(len(agents)) >= utils.RecallQuorum-utils.Epsilon
+}
+
+// holds a vote of no confidence in the rulers of a bike (its ruler or its council) if enough riders petition for it.
+// if the vote passes new rulers are elected among the other riders
+func (s *Server) RunRecallVote(bike objects.IMegaBike) {
+	protocol := s.governanceProtocol(bike)
+	rulers, ruled := protocol.InPower(bike)
+	if !ruled || len(rulers) == 0 {
+		return
+	}
+	candidates := slices.DeleteFunc(riderIDs(bike), func(id uuid.UUID) bool { return slices.Contains(rulers, id) })
+	// rulers riding alone can't be replaced
+	if len(candidates) == 0 || !s.collectRecallPetitions(bike, rulers) {
+		return
+	}
+
+	agents := bike.GetAgents()
+	against := 0
+	for _, agent := range agents {
+		if agent.VoteRecall() {
+			against++
+		}
+	}
+	share := float64(against) / float64(len(agents))
+	if share < protocol.RecallThreshold()-utils.Epsilon {
+		s.logEvent(RecallVoteEvent, bike.GetID(), fmt.Sprintf("rulers %v survived a vote of no confidence (%.2f against)", rulers, share))
+		return
+	}
+	// the recalled rulers can't stand again
+	if !slices.Contains(rulers, bike.GetRuler()) {
+		council := s.electCouncil(bike, candidates)
+		bike.SetCouncil(council)
+		s.councilElections[bike.GetID()] = s.round
+		s.logEvent(RulerRecalledEvent, bike.GetID(), fmt.Sprintf("council %v was removed (%.2f against), %v was elected", rulers, share, council))
+		return
+	}
+	newRuler := s.electRuler(agents, bike.GetGovernance(), candidates, bike.GetVoteMethod(utils.Election), s.tieBreaker(uuid.Nil, agents))
+	s.setRuler(bike, newRuler)
+	s.logEvent(RulerRecalledEvent, bike.GetID(), fmt.Sprintf("ruler %s was removed (%.2f against), %s was elected", rulers[0], share, newRuler))
+}
+
+// lets the riders of every ruled bike call a vote of no confidence in their rulers
+func (s *Server) RunRecallVotes() {
+	for _, bike := range s.GetMegaBikes() {
+		if len(bike.GetAgents()) != 0 {
+			s.RunRecallVote(bike)
+		}
+	}
+}
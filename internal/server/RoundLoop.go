@@ -6,25 +6,75 @@ import (
 	"SOMAS2023/internal/common/utils"
 	"SOMAS2023/internal/common/voting"
 	"fmt"
-	"slices"
 
 	"github.com/google/uuid"
 )
 
 func (s *Server) RunRoundLoop() {
+	s.round++
+	// message quotas and direction proposals are per round
+	clear(s.messagesSent)
+	clear(s.directionProposals)
+
 	// Capture dump of starting state
 	gameState := s.NewGameStateDump(0)
 	s.UpdateGameStates()
 
+	// agents repay the loans that are due and trade energy
+	s.RunEnergyTransfers()
+	s.UpdateGameStates()
+
+	// agents pay to check the claims made to them in the last round
+	s.RunAudits()
+	s.UpdateGameStates()
+
 	// get destination bikes from bikers not on bike
 	s.SetDestinationBikes()
 
+	// agents spend their points
+	s.RunPointsPurchases()
+	s.UpdateGameStates()
+
+	// agents can talk each other into staying or leaving
+	s.RunMessagingPhase(utils.BeforeLeaving)
+	s.UpdateGameStates()
+
 	// take care of agents that want to leave the bike and of the acceptance/ expulsion process
 	s.RunBikeSwitch(gameState)
 
+	// bikes that are due one hold a vote on their governance
+	s.RunConstitutionalVotes()
+	s.UpdateGameStates()
+
+	// riders of ruled bikes can hold a vote of no confidence in their ruler
+	s.RunRecallVotes()
+	s.UpdateGameStates()
+
+	// leaders that came to the end of their term face an election
+	s.RunScheduledElections()
+	s.UpdateGameStates()
+
+	// riders can propose changes to the rules of their bike
+	s.RunRuleAmendments()
+	s.UpdateGameStates()
+
+	// bikes aiming for the same lootbox negotiate how to share it
+	s.RunNegotiationSession()
+
+	// riders put up their plans and proposals
+	s.RunNoticeBoards()
+	s.UpdateGameStates()
+
+	// riders can canvass each other before they vote on the direction
+	s.RunMessagingPhase(utils.BeforeDirectionVote)
+	s.UpdateGameStates()
+
 	// get the direction decisions and pedalling forces
 	s.RunActionProcess()
 
+	// riders that didn't pedal as the rules of their bike say are sanctioned
+	s.EnforceRules()
+
 	// The Audi makes a decision
 	s.audi.UpdateGameState(gameState)
 
@@ -40,6 +90,10 @@ func (s *Server) RunRoundLoop() {
 
 	s.UpdateGameStates()
 
+	// riders can lobby each other before the loot is shared out
+	s.RunMessagingPhase(utils.BeforeAllocation)
+	s.UpdateGameStates()
+
 	// Lootbox Distribution
 	s.LootboxCheckAndDistributions()
 
@@ -52,17 +106,10 @@ func (s *Server) RunRoundLoop() {
 	s.unaliveAgents()
 	s.UpdateGameStates()
 
-	// if the leader dies hold new elections
+	// if the ruler (or a council member) dies appoint a new one
 	for _, bike := range s.GetMegaBikes() {
-		gov := bike.GetGovernance()
-		agents := bike.GetAgents()
-		if len(agents) != 0 && (gov == utils.Leadership || gov == utils.Dictatorship) {
-			ruler := bike.GetRuler()
-			if _, ok := s.deadAgents[ruler]; ok {
-				agents := bike.GetAgents()
-				ruler := s.RulerElection(agents, gov)
-				bike.SetRuler(ruler)
-			}
+		if s.rulerMissing(bike) {
+			s.appointRuler(bike)
 		}
 	}
 
@@ -100,52 +147,19 @@ func (s *Server) HandleKickoutProcess() []uuid.UUID {
 	for _, bike := range s.GetMegaBikes() {
 		agents := bike.GetAgents()
 		if len(agents) != 0 {
-
-			agentsVotes := make([]uuid.UUID, 0)
-
-			// the kickout process only happens democratically in level 0 and level 1
-			switch bike.GetGovernance() {
-			case utils.Democracy:
-				// make map of weights of 1 for all agents on bike
-				agents := bike.GetAgents()
-				weights := make(map[uuid.UUID]float64)
-				for _, agent := range agents {
-					weights[agent.GetID()] = 1.0
-				}
-
-				// get which agents are getting kicked out
-				agentsVotes = bike.KickOutAgent(weights)
-
-			case utils.Leadership:
-				// get the map of weights from the leader
-				ruler := bike.GetRuler()
-				leader := s.GetAgentMap()[ruler]
-				weights := leader.DecideWeights(utils.Kickout)
-				// get which agents are getting kicked out
-				agentsVotes = bike.KickOutAgent(weights)
-
-			case utils.Dictatorship:
-				// in level 2 only the ruler can kick out people
-				dictator := s.GetAgentMap()[bike.GetRuler()]
-				agentsVotes = dictator.DecideKickOut()
-			}
+			// get which agents are getting kicked out
+			agentsVotes := s.governanceProtocol(bike).Kickout(s, bike)
 
 			// perform kickout
-			leaderKickedOut := false
 			allKicked = append(allKicked, agentsVotes...)
 			for _, agentID := range agentsVotes {
 				fmt.Printf("kicking out agent %s\n", agentID)
 				s.RemoveAgentFromBike(s.GetAgentMap()[agentID])
-				// if the leader was kicked out vote for a new one
-				if agentID == bike.GetRuler() {
-					leaderKickedOut = true
-				}
 			}
 			s.UpdateGameStates()
-			if leaderKickedOut && len(bike.GetAgents()) != 0 && bike.GetGovernance() == utils.Leadership {
-				ruler := s.RulerElection(bike.GetAgents(), utils.Leadership)
-				bike.SetRuler(ruler)
-
+			// if the ruler (or a council member) was kicked out appoint a new one
+			if s.rulerMissing(bike) {
+				s.appointRuler(bike)
 			}
 		}
 
@@ -153,11 +167,15 @@ func (s *Server) HandleKickoutProcess() []uuid.UUID {
 	return allKicked
 }
 
-func (s *Server) GetLeavingDecisions(gameState objects.IGameState) []uuid.UUID {
+func (s *Server) GetLeavingDecisions(gameState GameStateDump) []uuid.UUID {
 	leavingAgents := make([]uuid.UUID, 0)
-	for agentId, agent := range s.GetAgentMap() {
+	agents := s.GetAgentMap()
+	// agents decide in ID order so that the noise on their views is drawn in the same order every game
+	for _, agentId := range sortedKeys(agents) {
+		agent := agents[agentId]
 		if agent.GetBikeStatus() {
-			agent.UpdateGameState(gameState)
+			// agents decide on the state of the game at the start of the round, as far as they can observe it
+			agent.UpdateGameState(s.gameStateFor(agent, gameState))
 			agent.UpdateAgentInternalState()
 			switch agent.DecideAction() {
 			case objects.Pedal:
@@ -180,9 +198,8 @@ func (s *Server) GetLeavingDecisions(gameState objects.IGameState) []uuid.UUID {
 	}
 	s.UpdateGameStates()
 	for _, bike := range s.GetMegaBikes() {
-		if slices.Contains(leavingAgents, bike.GetRuler()) && len(bike.GetAgents()) != 0 {
-			ruler := s.RulerElection(bike.GetAgents(), utils.Leadership)
-			bike.SetRuler(ruler)
+		if s.rulerMissing(bike) {
+			s.appointRuler(bike)
 		}
 	}
 	return leavingAgents
@@ -196,6 +213,11 @@ func (s *Server) ProcessJoiningRequests(inLimbo []uuid.UUID) {
 	// 2. pass to agents on each of the desired bikes a list of all agents trying to join
 	for bikeID, pendingAgents := range bikeRequests {
 		agents := s.megaBikes[bikeID].GetAgents()
+		if len(agents) != 0 {
+			// agents that paid to join skip the acceptance process
+			pendingAgents = s.admitPaidEntrants(s.megaBikes[bikeID], pendingAgents)
+			agents = s.megaBikes[bikeID].GetAgents()
+		}
 		if len(agents) == 0 {
 			for i, pendingAgent := range pendingAgents {
 				if i <= utils.BikersOnBike {
@@ -206,56 +228,11 @@ func (s *Server) ProcessJoiningRequests(inLimbo []uuid.UUID) {
 				}
 			}
 			s.UpdateGameStates() // agents need an updated game state if they want to have elections
-			// if the governance of the bike is ruler led an election needs to be held
-			gov := s.megaBikes[bikeID].GetGovernance()
-			if gov == utils.Dictatorship || gov == utils.Leadership {
-				// run election process
-				agents := s.megaBikes[bikeID].GetAgents()
-				ruler := s.RulerElection(agents, gov)
-				s.megaBikes[bikeID].SetRuler(ruler)
-			}
+			// the new riders appoint their ruler (or council)
+			s.appointRuler(s.megaBikes[bikeID])
 		} else {
 			bike := s.GetMegaBikes()[bikeID]
-			acceptedRanked := make([]uuid.UUID, 0)
-
-			switch bike.GetGovernance() {
-			case utils.Democracy:
-				// make map of weights of 1 for all agents on bike
-				weights := make(map[uuid.UUID]float64)
-				for _, agent := range agents {
-					weights[agent.GetID()] = 1.0
-				}
-
-				// get approval votes from each agent
-				responses := make(map[uuid.UUID](map[uuid.UUID]bool), len(agents)) // list containing all the agents' ranking
-				for _, agent := range agents {
-					responses[agent.GetID()] = agent.DecideJoining(pendingAgents)
-				}
-
-				// accept agents based on the response outcome (it will have to be a ranking system, as only 8-n bikers can be accepted)
-				acceptedRanked = voting.GetAcceptanceRanking(responses, weights)
-			case utils.Leadership:
-				// get the map of weights from the leader
-				leader := s.GetAgentMap()[bike.GetRuler()]
-				weights := leader.DecideWeights(utils.Joining)
-
-				// get approval votes from each agent
-				responses := make(map[uuid.UUID](map[uuid.UUID]bool), len(agents)) // list containing all the agents' ranking
-				for _, agent := range agents {
-					responses[agent.GetID()] = agent.DecideJoining(pendingAgents)
-				}
-
-				// accept agents based on the response outcome (it will have to be a ranking system, as only 8-n bikers can be accepted)
-				acceptedRanked = voting.GetAcceptanceRanking(responses, weights)
-			case utils.Dictatorship:
-				dictator := s.GetAgentMap()[bike.GetRuler()]
-				acceptedRankedMap := dictator.DecideJoining(pendingAgents)
-				for agentID, accepted := range acceptedRankedMap {
-					if accepted {
-						acceptedRanked = append(acceptedRanked, agentID)
-					}
-				}
-			}
+			acceptedRanked := s.governanceProtocol(bike).Joining(s, bike, pendingAgents)
 
 			// run acceptance process
 			totalSeatsFilled := len(agents)
@@ -280,38 +257,17 @@ func (s *Server) RunActionProcess() {
 
 		// get the direction for this round (either the voted on or what's decided by the leader/ dictator)
 		// for now it's actually just the elected lootbox (will change to accomodate for other proposal types)
-		var direction uuid.UUID
-		electedGovernance := bike.GetGovernance()
-		switch electedGovernance {
-		case utils.Democracy:
-			// make map of weights of 1 for all agents on bike
-			weights := make(map[uuid.UUID]float64)
-			for _, agent := range agents {
-				weights[agent.GetID()] = 1.0
-			}
-			direction = s.RunDemocraticAction(bike, weights)
-			for _, agent := range agents {
-				agent.UpdateEnergyLevel(-utils.DeliberativeDemocracyPenalty)
-			}
-		case utils.Leadership:
-			// get weights from leader
-			leader := s.GetAgentMap()[bike.GetRuler()]
-			weights := leader.DecideWeights(utils.Direction)
-			direction = s.RunDemocraticAction(bike, weights)
-			for _, agent := range agents {
-				agent.UpdateEnergyLevel(-utils.LeadershipDemocracyPenalty)
-			}
-		case utils.Dictatorship:
-			direction = s.RunRulerAction(bike)
-		}
+		direction := s.governanceProtocol(bike).Direction(s, bike)
 
 		for _, agent := range agents {
 			agent.DecideForce(direction)
 			// deplete energy
 			energyLost := agent.GetForces().Pedal * utils.MovingDepletion
 			agent.UpdateEnergyLevel(-energyLost)
+			s.pedalledEnergy[agent.GetID()] += energyLost
 		}
 	}
+	s.recordForces()
 }
 
 func (s *Server) MovePhysicsObject(po objects.IPhysicsObject) {
@@ -331,9 +287,8 @@ func (s *Server) MovePhysicsObject(po objects.IPhysicsObject) {
 	po.SetPhysicalState(finalState)
 }
 
+// returns uuid.Nil if no vote can be counted
 func (s *Server) GetWinningDirection(finalVotes map[uuid.UUID]voting.LootboxVoteMap, weights map[uuid.UUID]float64) uuid.UUID {
-	// get overall winner direction using chosen voting strategy
-
 	// this allows to get a slice of the interface from that of the specific type
 	// this way we can substitute agent.FInalDirectionVote with another function that returns
 	// another type of voting type which still implements INormaliseVoteMap
@@ -341,9 +296,14 @@ func (s *Server) GetWinningDirection(finalVotes map[uuid.UUID]voting.LootboxVote
 	for i, v := range finalVotes {
 		IfinalVotes[i] = v
 	}
+	direction, _ := s.getWinningDirection(IfinalVotes, weights, utils.VoteAction, s.tieBreaker(uuid.Nil, nil))
+	return direction
+}
 
+func (s *Server) getWinningDirection(finalVotes map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, method utils.VoteMethod, tieBreaker *voting.TieBreaker) (uuid.UUID, error) {
+	// get overall winner direction using chosen voting strategy
 	// TODO integrate voting functions from group 8
-	return voting.WinnerFromDist(IfinalVotes, weights)
+	return voting.WinnerFromDistWithMethod(finalVotes, weights, method, tieBreaker)
 }
 
 func (s *Server) AudiCollisionCheck() {
@@ -366,19 +326,21 @@ func (s *Server) AudiCollisionCheck() {
 
 func (s *Server) LootboxCheckAndDistributions() {
 
-	// checks how many bikes have looted one lootbox to split it between them
-	looted := make(map[uuid.UUID]int)
-	for _, megabike := range s.GetMegaBikes() {
+	// checks which bikes have looted one lootbox to split it between them
+	looted := make(map[uuid.UUID][]uuid.UUID)
+	for bikeid, megabike := range s.GetMegaBikes() {
 		for lootid, lootbox := range s.GetLootBoxes() {
 			if megabike.CheckForCollision(lootbox) { // && len(megabike.GetAgents()) != 0
-				if value, ok := looted[lootid]; ok {
-					looted[lootid] = value + 1
-				} else {
-					looted[lootid] = 1
-				}
+				looted[lootid] = append(looted[lootid], bikeid)
 			}
 		}
 	}
+	shares := make(map[uuid.UUID]map[uuid.UUID]float64, len(looted))
+	for lootid, bikes := range looted {
+		shares[lootid] = s.lootShares(lootid, bikes)
+	}
+	// agents that looted a box of their colour
+	collectors := make(map[uuid.UUID]struct{})
 	for bikeid, megabike := range s.GetMegaBikes() {
 		for lootid, lootbox := range s.GetLootBoxes() {
 			if megabike.CheckForCollision(lootbox) {
@@ -388,62 +350,13 @@ func (s *Server) LootboxCheckAndDistributions() {
 				totAgents := len(agents)
 
 				if totAgents > 0 {
-					gov := s.GetMegaBikes()[bikeid].GetGovernance()
-					var winningAllocation voting.IdVoteMap
-					switch gov {
-					case utils.Democracy:
-						allAllocations := make(map[uuid.UUID]voting.IdVoteMap)
-						for _, agent := range agents {
-							// the agents return their ideal lootbox split by assigning a number between 0 and 1 to
-							// each biker on their bike (including themselves)
-							allAllocations[agent.GetID()] = agent.DecideAllocation()
-						}
+					winningAllocation := s.lootAllocation(megabike)
 
-						Iallocations := make(map[uuid.UUID]voting.IVoter)
-						for i, v := range allAllocations {
-							Iallocations[i] = v
-						}
-						// TODO handle error
-						// make weights of 1 for all agents
-						weights := make(map[uuid.UUID]float64)
-						for _, agent := range agents {
-							weights[agent.GetID()] = 1.0
-						}
-						winningAllocation = voting.CumulativeDist(Iallocations, weights)
-					case utils.Leadership:
-						// get the map of weights from the leader
-						leader := s.GetAgentMap()[megabike.GetRuler()]
-						weights := leader.DecideWeights(utils.Allocation)
-					outer:
-						for id := range weights {
-							for _, agent := range agents {
-								if agent.GetID() == id {
-									continue outer
-								}
-							}
-							panic("leader gave weight to an agent that isn't on the bike")
-						}
-						// get allocation votes from each agent
-						allAllocations := make(map[uuid.UUID]voting.IdVoteMap)
-						for _, agent := range agents {
-							allAllocations[agent.GetID()] = agent.DecideAllocation()
-						}
-						Iallocations := make(map[uuid.UUID]voting.IVoter)
-						for i, v := range allAllocations {
-							Iallocations[i] = v
-						}
-						winningAllocation = voting.CumulativeDist(Iallocations, weights)
-					case utils.Dictatorship:
-						// dictator decides the allocation
-						leader := s.GetAgentMap()[megabike.GetRuler()]
-						winningAllocation = leader.DecideDictatorAllocation()
-					}
-
-					bikeShare := float64(looted[lootid]) // how many other bikes have looted this box
+					bikeShare := shares[lootid][bikeid] // the share of the box this bike gets (split with the other bikes that looted it)
 
 					for agentID, allocation := range winningAllocation {
 						fmt.Printf("total loot: %f \n", lootbox.GetTotalResources())
-						lootShare := allocation * (lootbox.GetTotalResources() / bikeShare)
+						lootShare := allocation * (lootbox.GetTotalResources() * bikeShare)
 						agent := s.GetAgentMap()[agentID]
 						// Allocate loot based on the calculated utility share
 						fmt.Printf("Agent %s allocated %f loot \n", agent.GetID(), lootShare)
@@ -451,6 +364,7 @@ func (s *Server) LootboxCheckAndDistributions() {
 						// Allocate points if the box is of the right colour
 						if agent.GetColour() == lootbox.GetColour() {
 							agent.UpdatePoints(utils.PointsFromSameColouredLootBox)
+							collectors[agentID] = struct{}{}
 						}
 					}
 				}
@@ -458,12 +372,14 @@ func (s *Server) LootboxCheckAndDistributions() {
 		}
 	}
 
-	// despawn lootboxes that have been looted
-	for id, loot := range looted {
-		if loot > 0 {
-			delete(s.lootBoxes, id)
-		}
+	// settle the agreements on the looted lootboxes and despawn them
+	for id, bikes := range looted {
+		s.settleNegotiationAgreements(id, bikes)
+		delete(s.lootBoxes, id)
 	}
+
+	// agents that looted a box of their colour seek a new one
+	s.updateAgentColours(collectors)
 }
 
 func (s *Server) SetDestinationBikes() {
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// lets the riders of every bike propose changes to its rules, which the bike makes or not the way it takes its decisions
+func (s *Server) RunRuleAmendments() {
+	for _, bike := range s.GetMegaBikes() {
+		for _, rider := range bike.GetAgents() {
+			for _, amendment := range rider.ProposeRuleAmendments() {
+				if rider.GetEnergyLevel() < utils.RuleAmendmentCost {
+					fmt.Printf("Agent %s can't afford to propose a change to the rules \n", rider.GetID())
+					break
+				}
+				rider.UpdateEnergyLevel(-utils.RuleAmendmentCost)
+				amendment.Proposer = rider.GetID()
+				s.RunRuleAmendment(bike, amendment)
+			}
+		}
+	}
+}
+
+// puts a change to the rules of a bike to the vote, and makes it if it passes. Returns whether the rules changed
+func (s *Server) RunRuleAmendment(bike objects.IMegaBike, amendment objects.RuleAmendment) bool {
+	rules := bike.GetRules()
+	i := slices.IndexFunc(rules, func(rule objects.Rule) bool { return rule.ID == amendment.Rule.ID })
+	switch {
+	case !knownAmendmentAction(amendment.Action):
+		s.penaliseAgent(bike.GetID(), amendment.Proposer, fmt.Sprintf("proposed an unknown change to the rules (%d)", amendment.Action))
+		return false
+	case amendment.Action != objects.RepealRule && !knownRuleKind(amendment.Rule.Kind):
+		s.penaliseAgent(bike.GetID(), amendment.Proposer, fmt.Sprintf("proposed to %s a rule of an unknown kind (%d)", amendment.Action, amendment.Rule.Kind))
+		return false
+	case amendment.Action != objects.EnactRule && i < 0:
+		fmt.Printf("Agent %s proposed to %s a rule bike %s doesn't have \n", amendment.Proposer, amendment.Action, bike.GetID())
+		return false
+	case amendment.Action == objects.EnactRule && len(rules) >= utils.MaxRules:
+		fmt.Printf("Agent %s proposed a rule, but bike %s has as many as it can have \n", amendment.Proposer, bike.GetID())
+		return false
+	}
+	if amendment.Action != objects.RepealRule {
+		amendment.Rule = sanitiseRule(amendment.Rule)
+	}
+
+	if !s.governanceProtocol(bike).Amendment(s, bike, amendment) {
+		s.logEvent(RuleVoteEvent, bike.GetID(), fmt.Sprintf("rejected the proposal of %s to %s a %s rule", amendment.Proposer, amendment.Action, amendment.Rule.Kind))
+		return false
+	}
+	rule := amendment.Rule
+	rule.Enacted = s.round
+	switch amendment.Action {
+	case objects.EnactRule:
+		rule.ID = uuid.New()
+		rules = append(rules, rule)
+	case objects.AmendRule:
+		rules[i] = rule
+	case objects.RepealRule:
+		rule = rules[i]
+		rules = slices.Delete(rules, i, i+1)
+	}
+	bike.SetRules(rules)
+	s.logEvent(RuleChangeEvent, bike.GetID(), fmt.Sprintf("passed the proposal of %s to %s a %s rule (value %.2f, fine %.2f)", amendment.Proposer, amendment.Action, rule.Kind, rule.Value, rule.Fine))
+	return true
+}
+
+// whether the server knows how to make a change to the rules
+func knownAmendmentAction(action objects.AmendmentAction) bool {
+	return action >= objects.EnactRule && action <= objects.RepealRule
+}
+
+// whether the server knows how to enforce a kind of rule
+func knownRuleKind(kind objects.RuleKind) bool {
+	return kind >= objects.MinimumPedalRule && kind <= objects.ViolationKickoutRule
+}
+
+// keeps the values of a rule within what makes sense for its kind
+func sanitiseRule(rule objects.Rule) objects.Rule {
+	rule.Fine = math.Max(rule.Fine, 0.0)
+	switch rule.Kind {
+	case objects.MinimumPedalRule:
+		rule.Value = math.Min(math.Max(rule.Value, 0.0), 1.0)
+	case objects.ViolationKickoutRule:
+		rule.Value = math.Max(math.Round(rule.Value), 1.0)
+	}
+	return rule
+}
+
+// checks the forces the riders of every bike just applied against the rules of their bike. Riders that break a rule
+// are fined, and kicked off if the bike has a rule against repeat offenders
+func (s *Server) EnforceRules() {
+	for bikeID, bike := range s.GetMegaBikes() {
+		rules := bike.GetRules()
+		if len(rules) == 0 {
+			continue
+		}
+		for _, rider := range bike.GetAgents() {
+			for _, rule := range rules {
+				if rule.Kind == objects.MinimumPedalRule && rider.GetForces().Pedal < rule.Value-utils.Epsilon {
+					s.sanction(bikeID, rider, rule)
+				}
+			}
+		}
+
+		kicked := false
+		for _, rule := range rules {
+			if rule.Kind != objects.ViolationKickoutRule {
+				continue
+			}
+			for _, rider := range bike.GetAgents() {
+				violations := s.ruleViolations[bikeID][rider.GetID()]
+				if float64(violations) >= rule.Value-utils.Epsilon {
+					s.logEvent(RuleSanctionEvent, bikeID, fmt.Sprintf("kicked out %s after %d violations of the rules", rider.GetID(), violations))
+					delete(s.ruleViolations[bikeID], rider.GetID())
+					s.RemoveAgentFromBike(rider)
+					kicked = true
+				}
+			}
+		}
+		if kicked && s.rulerMissing(bike) {
+			s.UpdateGameStates()
+			s.appointRuler(bike)
+		}
+	}
+}
+
+// records that a rider broke a rule of its bike, and fines it
+func (s *Server) sanction(bikeID uuid.UUID, rider objects.IBaseBiker, rule objects.Rule) {
+	if _, ok := s.ruleViolations[bikeID]; !ok {
+		s.ruleViolations[bikeID] = make(map[uuid.UUID]int)
+	}
+	s.ruleViolations[bikeID][rider.GetID()]++
+	rider.UpdateEnergyLevel(-rule.Fine)
+	s.logEvent(RuleSanctionEvent, bikeID, fmt.Sprintf("fined %s %.2f for breaking the %s rule", rider.GetID(), rule.Fine, rule.Kind))
+}
+
+// the allocation of the loot of a bike: in proportion to the effort of the riders if they made that a rule,
+// otherwise the one its governance decides
+func (s *Server) lootAllocation(bike objects.IMegaBike) voting.IdVoteMap {
+	effortShare := slices.ContainsFunc(bike.GetRules(), func(rule objects.Rule) bool {
+		return rule.Kind == objects.EffortShareRule
+	})
+	if effortShare {
+		allocation := make(voting.IdVoteMap, len(bike.GetAgents()))
+		total := 0.0
+		for _, agent := range bike.GetAgents() {
+			allocation[agent.GetID()] = s.pedalledEnergy[agent.GetID()]
+			total += allocation[agent.GetID()]
+		}
+		// before anyone has pedalled the riders decide as usual
+		if total > 0 {
+			for id := range allocation {
+				allocation[id] /= total
+			}
+			return allocation
+		}
+	}
+	return s.governanceProtocol(bike).Allocation(s, bike)
+}
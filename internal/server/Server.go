@@ -6,6 +6,7 @@ import (
 	"SOMAS2023/internal/common/voting"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"os"
 
 	baseserver "github.com/MattSScott/basePlatformSOMAS/BaseServer"
@@ -27,9 +28,51 @@ type IBaseBikerServer interface {
 	RunRulerAction(bike objects.IMegaBike) uuid.UUID
 	RunDemocraticAction(bike objects.IMegaBike, weights map[uuid.UUID]float64) uuid.UUID
 	NewGameStateDump(iteration int) GameStateDump
-	GetLeavingDecisions(gameState objects.IGameState) []uuid.UUID
+	GetLeavingDecisions(gameState GameStateDump) []uuid.UUID
 	HandleKickoutProcess() []uuid.UUID
 	ProcessJoiningRequests(inLimbo []uuid.UUID)
+	RunEnergyTransfers()
+	GetEnergyLedger() []objects.EnergyTransaction
+	GetOutstandingLoans() []objects.EnergyLoan
+	RunPointsPurchases()
+	GetPointsLedger() []objects.PointsTransaction
+	RunAudits()
+	SetMessagingLimits(limits utils.MessagingLimits)
+	GetDroppedMessages(agentID uuid.UUID) int
+	RunMessagingSession()
+	RunMessagingPhase(phase utils.MessagingPhase)
+	SetMessagingPhases(phases ...utils.MessagingPhase)
+	SetMessageChannel(channel utils.MessageChannel)
+	GetMessageLog() []MessageRecord
+	RunNoticeBoards()
+	RunRuleAmendments()
+	RunRuleAmendment(bike objects.IMegaBike, amendment objects.RuleAmendment) bool
+	EnforceRules()
+	Audit(request objects.AuditRequest) objects.AuditOutcome
+	SetColourPolicy(policy utils.ColourPolicy)
+	SetObservability(observability utils.Observability)
+	SetSensorModel(sensors utils.SensorModel)
+	NewGameStateView(gs GameStateDump, observer uuid.UUID) GameStateView
+	UpdateAgentColour(agent objects.IBaseBiker)
+	GetColourHistory(agent objects.IBaseBiker) []utils.Colour
+	RunConstitutionalVotes()
+	RunConstitutionalVote(bike objects.IMegaBike)
+	RunVoteMethodVote(bike objects.IMegaBike)
+	RunRecallVotes()
+	RunRecallVote(bike objects.IMegaBike)
+	RunScheduledElections()
+	RunLeadershipElection(bike objects.IMegaBike)
+	GetRulerTerm(bikeID uuid.UUID) (RulerTerm, bool)
+	RegisterGovernanceProtocol(governance utils.Governance, protocol IGovernanceProtocol)
+	UnregisterGovernanceProtocol(governance utils.Governance)
+	GetGovernanceProtocol(governance utils.Governance) (IGovernanceProtocol, bool)
+	GetEvents() []GameEvent
+	SetVoteAnalysis(enabled bool)
+	GetVoteAnalyses() []VoteAnalysis
+	RunNegotiationSession()
+	GetContestedLootBoxes() map[uuid.UUID][]uuid.UUID
+	GetNegotiator(bike objects.IMegaBike) objects.IBaseBiker
+	GetNegotiationAgreements() map[uuid.UUID][]NegotiationAgreement
 	RunActionProcess()
 	AudiCollisionCheck()
 	AddAgentToBike(agent objects.IBaseBiker)
@@ -51,6 +94,81 @@ type Server struct {
 	audi            objects.IAudi
 	deadAgents      map[uuid.UUID]objects.IBaseBiker
 	foundingChoices map[uuid.UUID]utils.Governance
+	// governanceProtocols maps a governance to the protocol the bikes with that governance take their decisions with
+	governanceProtocols map[utils.Governance]IGovernanceProtocol
+	// negotiationAgreements maps a lootbox ID to the agreements bikes have reached on sharing it
+	negotiationAgreements map[uuid.UUID][]NegotiationAgreement
+	// directionProposals maps an agent ID to the lootbox it proposed as direction in the current round
+	directionProposals map[uuid.UUID]uuid.UUID
+	// round is the number of rounds played in the current game
+	round int
+	// energyLedger records every energy transfer between agents in the current game
+	energyLedger []objects.EnergyTransaction
+	loans        map[uuid.UUID]objects.EnergyLoan
+	// pointsLedger records every points purchase in the current game
+	pointsLedger []objects.PointsTransaction
+	// purchasedVoteWeight is the extra vote weight agents bought for the current round
+	purchasedVoteWeight map[uuid.UUID]float64
+	// paidEntries maps agents that paid to join a bike to the ID of that bike
+	paidEntries map[uuid.UUID]uuid.UUID
+	// colourPolicy decides the new colour of agents that looted a box of their colour
+	colourPolicy utils.ColourPolicy
+	// colourHistory maps an agent ID to the colours it sought in the current game (only once its colour changed)
+	colourHistory map[uuid.UUID][]utils.Colour
+	// lastGovernanceChange maps a bike ID to the round its governance last changed in a constitutional vote
+	lastGovernanceChange map[uuid.UUID]int
+	// rulerTerms maps a bike ID to the term its ruler is serving
+	rulerTerms map[uuid.UUID]RulerTerm
+	// councilElections maps the ID of a bike governed by a council to the round its council was elected in
+	councilElections map[uuid.UUID]int
+	// events records what happened to the institutions of the bikes in the current game
+	events []GameEvent
+	// tieBreakRand draws the winners of tied votes, it is seeded with utils.TieBreakSeed every game
+	tieBreakRand *rand.Rand
+	// lastDirections maps a bike ID to the lootbox its riders last voted to ride to
+	lastDirections map[uuid.UUID]uuid.UUID
+	// voteAnalysis decides whether direction votes and elections are analysed for strategic voting
+	voteAnalysis bool
+	// voteAnalyses records the analysis of every vote in the current game
+	voteAnalyses []VoteAnalysis
+	// boardingRounds maps a rider ID to the round it got on its current bike
+	boardingRounds map[uuid.UUID]int
+	// pedalledEnergy maps a rider ID to the energy it spent pedalling its current bike
+	pedalledEnergy map[uuid.UUID]float64
+	// observability decides how much of the game state each agent is shown
+	observability utils.Observability
+	// observationRand draws the noise on what agents see of each other, it is seeded with utils.ObservationSeed every game
+	observationRand *rand.Rand
+	// sensors decides how accurately agents sense the game state
+	sensors utils.SensorModel
+	// forceHistory holds the forces of every agent in the last rounds, oldest first
+	forceHistory []map[uuid.UUID]utils.Forces
+	// appliedForces maps a round to the forces every agent applied in it, audits check claims against them
+	appliedForces map[int]map[uuid.UUID]utils.Forces
+	// castBallots maps the kind of a vote and a round to the last ballot every agent cast in it, audits check claims against them
+	castBallots map[objects.AuditClaimType]map[int]map[uuid.UUID]voting.IdVoteMap
+	// messagingLimits are the limits on the messages agents send each other
+	messagingLimits utils.MessagingLimits
+	// messagesSent maps an agent ID to the number of messages it sent in the current round
+	messagesSent map[uuid.UUID]int
+	// droppedMessages maps an agent ID to the number of deliveries of its messages dropped in the current game
+	droppedMessages map[uuid.UUID]int
+	// messagingPhases are the phases of the round in which agents message each other
+	messagingPhases []utils.MessagingPhase
+	// messageChannel is how late messages arrive and how often they are lost
+	messageChannel utils.MessageChannel
+	// messagingRand draws the lost messages, it is seeded with utils.MessagingSeed every game
+	messagingRand *rand.Rand
+	// messagingSession counts the messaging phases run in the current game, to time the delivery of messages
+	messagingSession int
+	// pendingMessages are the messages sent but not delivered yet
+	pendingMessages []pendingMessage
+	// messageLog records every message delivered in the current game
+	messageLog []MessageRecord
+	// settledRound is the last round whose events have all happened, the claims made about it can be checked
+	settledRound int
+	// ruleViolations maps a bike ID to the number of times each of its riders broke its rules in the current game
+	ruleViolations map[uuid.UUID]map[uuid.UUID]int
 }
 
 func Initialize(iterations int) IBaseBikerServer {
@@ -61,6 +179,56 @@ func Initialize(iterations int) IBaseBikerServer {
 		megaBikeRiders: make(map[uuid.UUID]uuid.UUID),
 		deadAgents:     make(map[uuid.UUID]objects.IBaseBiker),
 		audi:           objects.GetIAudi(),
+
+		governanceProtocols:   defaultGovernanceProtocols(),
+		negotiationAgreements: make(map[uuid.UUID][]NegotiationAgreement),
+		directionProposals:    make(map[uuid.UUID]uuid.UUID),
+		energyLedger:          make([]objects.EnergyTransaction, 0),
+		loans:                 make(map[uuid.UUID]objects.EnergyLoan),
+		pointsLedger:          make([]objects.PointsTransaction, 0),
+		purchasedVoteWeight:   make(map[uuid.UUID]float64),
+		paidEntries:           make(map[uuid.UUID]uuid.UUID),
+		colourPolicy:          utils.ColourChangePolicy,
+		colourHistory:         make(map[uuid.UUID][]utils.Colour),
+		lastGovernanceChange:  make(map[uuid.UUID]int),
+		rulerTerms:            make(map[uuid.UUID]RulerTerm),
+		councilElections:      make(map[uuid.UUID]int),
+		events:                make([]GameEvent, 0),
+		tieBreakRand:          rand.New(rand.NewSource(utils.TieBreakSeed)),
+		lastDirections:        make(map[uuid.UUID]uuid.UUID),
+		voteAnalysis:          utils.AnalyseVotes,
+		voteAnalyses:          make([]VoteAnalysis, 0),
+		boardingRounds:        make(map[uuid.UUID]int),
+		pedalledEnergy:        make(map[uuid.UUID]float64),
+		observability:         utils.GameStateObservability,
+		observationRand:       rand.New(rand.NewSource(utils.ObservationSeed)),
+		sensors: utils.SensorModel{
+			PositionNoise: utils.PositionNoise,
+			LootBoxRange:  utils.LootBoxRange,
+			ForceDelay:    utils.ForceDelay,
+		},
+		forceHistory:  make([]map[uuid.UUID]utils.Forces, 0),
+		appliedForces: make(map[int]map[uuid.UUID]utils.Forces),
+		castBallots:   make(map[objects.AuditClaimType]map[int]map[uuid.UUID]voting.IdVoteMap),
+		messagingLimits: utils.MessagingLimits{
+			Quota:         utils.MessageQuota,
+			MessageCost:   utils.MessageCost,
+			RecipientCost: utils.MessageRecipientCost,
+			MaxRecipients: utils.MaxMessageRecipients,
+			Range:         utils.MessageRange,
+		},
+		messagesSent:    make(map[uuid.UUID]int),
+		droppedMessages: make(map[uuid.UUID]int),
+		messagingPhases: []utils.MessagingPhase{utils.EndOfRound},
+		messageChannel:  utils.MessageChannel{Delay: utils.MessageDelay, DropRate: utils.MessageDropRate},
+		messagingRand:   rand.New(rand.NewSource(utils.MessagingSeed)),
+		pendingMessages: make([]pendingMessage, 0),
+		messageLog:      make([]MessageRecord, 0),
+		settledRound:    -1,
+		ruleViolations:  make(map[uuid.UUID]map[uuid.UUID]int),
+	}
+	if utils.InterleavedMessaging {
+		server.messagingPhases = []utils.MessagingPhase{utils.BeforeLeaving, utils.BeforeDirectionVote, utils.BeforeAllocation, utils.EndOfRound}
 	}
 	server.replenishLootBoxes()
 	server.replenishMegaBikes()
@@ -78,6 +246,8 @@ func (s *Server) RemoveAgent(agent objects.IBaseBiker) {
 		s.megaBikes[bikeId].RemoveAgent(id)
 		delete(s.megaBikeRiders, id)
 	}
+	delete(s.boardingRounds, id)
+	delete(s.pedalledEnergy, id)
 }
 
 func (s *Server) AddAgentToBike(agent objects.IBaseBiker) {
@@ -88,6 +258,10 @@ func (s *Server) AddAgentToBike(agent objects.IBaseBiker) {
 
 	// set agent on desired bike
 	bikeId := agent.GetBike()
+	if oldBikeId, ok := s.megaBikeRiders[agent.GetID()]; !ok || oldBikeId != bikeId {
+		s.boardingRounds[agent.GetID()] = s.round
+		delete(s.pedalledEnergy, agent.GetID())
+	}
 	s.megaBikes[bikeId].AddAgent(agent)
 	s.megaBikeRiders[agent.GetID()] = bikeId
 	if !agent.GetBikeStatus() {
@@ -105,6 +279,8 @@ func (s *Server) RemoveAgentFromBike(agent objects.IBaseBiker) {
 	if _, ok := s.megaBikeRiders[agent.GetID()]; ok {
 		delete(s.megaBikeRiders, agent.GetID())
 	}
+	delete(s.boardingRounds, agent.GetID())
+	delete(s.pedalledEnergy, agent.GetID())
 }
 
 func (s *Server) GetDeadAgents() map[uuid.UUID]objects.IBaseBiker {
@@ -144,36 +320,30 @@ func (s *Server) outputResults(gameStates [][]GameStateDump) {
 	if err := encoder.Encode(flattenedGameStates); err != nil {
 		panic(err)
 	}
-}
 
-func (s *Server) UpdateGameStates() {
-	gs := s.NewGameStateDump(0)
-	for _, agent := range s.GetAgentMap() {
-		agent.UpdateGameState(gs)
+	// the messages delivered in each game
+	messageLog := make([][]MessageRecord, 0, len(gameStates))
+	for _, game := range gameStates {
+		messageLog = append(messageLog, gameMessages(game))
+	}
+	file, err = os.Create("message_log.json")
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	encoder = json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(messageLog); err != nil {
+		panic(err)
 	}
 }
 
-// had to override to address the fact that agents only have access to the game dump
-// version of agents, so if the recipients are set to be those it will panic as they
-// can't call the handler functions
-func (s *Server) RunMessagingSession() {
-	agentArray := s.GenerateAgentArrayFromMap()
-
-	for _, agent := range s.GetAgentMap() {
-		allMessages := agent.GetAllMessages(agentArray)
-		for _, msg := range allMessages {
-			recipients := msg.GetRecipients()
-			// make recipient list with actual agents
-			usableRecipients := make([]objects.IBaseBiker, len(recipients))
-			for i, recipient := range recipients {
-				usableRecipients[i] = s.GetAgentMap()[recipient.GetID()]
-			}
-			for _, recip := range usableRecipients {
-				if agent.GetID() == recip.GetID() {
-					continue
-				}
-				msg.InvokeMessageHandler(recip)
-			}
-		}
+func (s *Server) UpdateGameStates() {
+	gs := s.NewGameStateDump(0)
+	agents := s.GetAgentMap()
+	// the views are built in ID order so that the observation noise is drawn in the same order every game
+	for _, id := range sortedKeys(agents) {
+		agent := agents[id]
+		agent.UpdateGameState(s.gameStateFor(agent, gs))
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"math"
+	"math/rand"
 	"slices"
 
 	"github.com/google/uuid"
@@ -55,6 +56,39 @@ func (s *Server) ResetGameState() {
 	// empty the dead agent map
 	clear(s.deadAgents)
 
+	// agreements on lootboxes and debts don't carry over between games
+	clear(s.negotiationAgreements)
+	clear(s.directionProposals)
+	clear(s.loans)
+	s.energyLedger = make([]objects.EnergyTransaction, 0)
+	s.pointsLedger = make([]objects.PointsTransaction, 0)
+	clear(s.colourHistory)
+	clear(s.lastGovernanceChange)
+	s.events = make([]GameEvent, 0)
+	s.round = 0
+	// every game breaks ties with the same sequence of draws
+	s.tieBreakRand = rand.New(rand.NewSource(utils.TieBreakSeed))
+	clear(s.lastDirections)
+	s.voteAnalyses = make([]VoteAnalysis, 0)
+	clear(s.boardingRounds)
+	clear(s.pedalledEnergy)
+	s.observationRand = rand.New(rand.NewSource(utils.ObservationSeed))
+	s.forceHistory = make([]map[uuid.UUID]utils.Forces, 0)
+	clear(s.appliedForces)
+	clear(s.castBallots)
+	clear(s.messagesSent)
+	clear(s.droppedMessages)
+	s.messagingRand = rand.New(rand.NewSource(utils.MessagingSeed))
+	s.messagingSession = 0
+	s.pendingMessages = make([]pendingMessage, 0)
+	s.messageLog = make([]MessageRecord, 0)
+	s.settledRound = -1
+	for _, bike := range s.megaBikes {
+		bike.SetNotices([]objects.Notice{})
+		bike.SetRules([]objects.Rule{})
+	}
+	clear(s.ruleViolations)
+
 	// zero the points (conditional)
 	if utils.ResetPointsEveryRound {
 		for _, agent := range s.GetAgentMap() {
@@ -63,8 +97,10 @@ func (s *Server) ResetGameState() {
 	}
 
 	for _, bike := range s.GetMegaBikes() {
-		bike.SetRuler(uuid.Nil)
+		s.setRuler(bike, uuid.Nil)
 	}
+	clear(s.rulerTerms)
+	clear(s.councilElections)
 
 	s.replenishLootBoxes()
 	s.replenishMegaBikes()
@@ -135,13 +171,11 @@ func (s *Server) FoundingInstitutions() {
 	}
 
 	s.UpdateGameStates()
-	// run election process for Leadership and Dictatorship bikes
+	// choose the voting methods and appoint the rulers (or councils) of the bikes
 	for _, bike := range s.GetMegaBikes() {
-		gov := bike.GetGovernance()
-		agents := bike.GetAgents()
-		if (gov == utils.Leadership || gov == utils.Dictatorship) && len(agents) != 0 {
-			ruler := s.RulerElection(agents, gov)
-			bike.SetRuler(ruler)
+		if len(bike.GetAgents()) != 0 {
+			s.foundVoteMethods(bike)
+			s.appointRuler(bike)
 		}
 	}
 
@@ -1,15 +1,19 @@
 package server
 
 import (
+	"SOMAS2023/internal/common/objects"
 	"math"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/tealeg/xlsx/v3"
 )
 
 type GameStatistics struct {
-	PerRound []AgentStatistics `json:"per_round"`
-	Average  AgentStatistics   `json:"average"`
+	PerRound []AgentStatistics   `json:"per_round"`
+	Average  AgentStatistics     `json:"average"`
+	Votes    []VoteStatistics    `json:"votes"`
+	Network  []NetworkStatistics `json:"network"`
 }
 
 type AgentStatistics struct {
@@ -18,16 +22,45 @@ type AgentStatistics struct {
 	AgentEnergyVariance map[uuid.UUID]float64 `json:"agent_energy_variance"`
 	AgentPointsAverage  map[uuid.UUID]float64 `json:"agent_points_average"`
 	AgentPointsVariance map[uuid.UUID]float64 `json:"agent_points_variance"`
+	// the number of analysed votes in which the agent could have changed the outcome on its own
+	AgentManipulations map[uuid.UUID]float64 `json:"agent_manipulations"`
+	// the number of deliveries of the agent's messages the server had dropped by the end of the round
+	AgentMessagesDropped map[uuid.UUID]float64 `json:"agent_messages_dropped"`
+	// the number of agents the agent sent messages to or got messages from
+	AgentDegree map[uuid.UUID]float64 `json:"agent_degree"`
+}
+
+// how open to strategic voting the analysed votes were
+type VoteStatistics struct {
+	Votes                int     `json:"votes"`
+	ManipulableShare     float64 `json:"manipulable_share"`      // votes a single voter could have changed
+	CoalitionShare       float64 `json:"coalition_share"`        // votes a coalition could have changed
+	CondorcetWinnerShare float64 `json:"condorcet_winner_share"` // votes with a Condorcet winner that elected it
+	AverageMargin        float64 `json:"average_margin"`
+}
+
+// the shape of the network of agents that messaged each other
+type NetworkStatistics struct {
+	Messages      int     `json:"messages"`       // messages delivered, once per recipient
+	Links         int     `json:"links"`          // pairs of a sender and a recipient
+	AverageDegree float64 `json:"average_degree"` // agents each agent talked to
+	Reciprocity   float64 `json:"reciprocity"`    // links whose recipient also messaged the sender
+	Clustering    float64 `json:"clustering"`     // average clustering coefficient of the agents with two or more neighbours
+	InTeamShare   float64 `json:"in_team_share"`  // messages between agents of the same team
+	HonestyRate   float64 `json:"honesty_rate"`   // messages making a claim the server could check that were true
 }
 
 type AgentStatisticAccessor func(statistics *AgentStatistics) map[uuid.UUID]float64
 
 var (
-	getLifetime       = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentLifetime }
-	getEnergyAverage  = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentEnergyAverage }
-	getEnergyVariance = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentEnergyVariance }
-	getPointsAverage  = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentPointsAverage }
-	getPointsVariance = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentPointsVariance }
+	getLifetime        = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentLifetime }
+	getEnergyAverage   = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentEnergyAverage }
+	getEnergyVariance  = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentEnergyVariance }
+	getPointsAverage   = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentPointsAverage }
+	getPointsVariance  = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentPointsVariance }
+	getManipulations   = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentManipulations }
+	getMessagesDropped = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentMessagesDropped }
+	getDegree          = func(statistics *AgentStatistics) map[uuid.UUID]float64 { return statistics.AgentDegree }
 )
 
 func averageStatisticsOverRounds(statisticsPerRound []AgentStatistics, accessor AgentStatisticAccessor) map[uuid.UUID]float64 {
@@ -53,26 +86,211 @@ func CalculateStatistics(gameStates [][]GameStateDump) GameStatistics {
 	getAgentPoints := func(agent *AgentDump) float64 { return float64(agent.Points) }
 
 	statisticsPerRound := make([]AgentStatistics, 0, len(gameStates))
+	votesPerRound := make([]VoteStatistics, 0, len(gameStates))
+	networkPerRound := make([]NetworkStatistics, 0, len(gameStates))
 	for _, round := range gameStates {
+		votesPerRound = append(votesPerRound, voteStatistics(round))
+		networkPerRound = append(networkPerRound, networkStatistics(round))
 		statisticsPerRound = append(statisticsPerRound, AgentStatistics{
-			AgentLifetime:       agentLifetime(round),
-			AgentEnergyAverage:  agentAverage(round, getAgentEnergy),
-			AgentEnergyVariance: agentVariance(round, getAgentEnergy),
-			AgentPointsAverage:  agentAverage(round, getAgentPoints),
-			AgentPointsVariance: agentVariance(round, getAgentPoints),
+			AgentLifetime:        agentLifetime(round),
+			AgentEnergyAverage:   agentAverage(round, getAgentEnergy),
+			AgentEnergyVariance:  agentVariance(round, getAgentEnergy),
+			AgentPointsAverage:   agentAverage(round, getAgentPoints),
+			AgentPointsVariance:  agentVariance(round, getAgentPoints),
+			AgentManipulations:   agentManipulations(round),
+			AgentMessagesDropped: agentMessagesDropped(round),
+			AgentDegree:          agentDegree(round),
 		})
 	}
 
 	return GameStatistics{
 		PerRound: statisticsPerRound,
 		Average: AgentStatistics{
-			AgentLifetime:       averageStatisticsOverRounds(statisticsPerRound, getLifetime),
-			AgentEnergyAverage:  averageStatisticsOverRounds(statisticsPerRound, getEnergyAverage),
-			AgentEnergyVariance: averageStatisticsOverRounds(statisticsPerRound, getEnergyVariance),
-			AgentPointsAverage:  averageStatisticsOverRounds(statisticsPerRound, getPointsAverage),
-			AgentPointsVariance: averageStatisticsOverRounds(statisticsPerRound, getPointsVariance),
+			AgentLifetime:        averageStatisticsOverRounds(statisticsPerRound, getLifetime),
+			AgentEnergyAverage:   averageStatisticsOverRounds(statisticsPerRound, getEnergyAverage),
+			AgentEnergyVariance:  averageStatisticsOverRounds(statisticsPerRound, getEnergyVariance),
+			AgentPointsAverage:   averageStatisticsOverRounds(statisticsPerRound, getPointsAverage),
+			AgentPointsVariance:  averageStatisticsOverRounds(statisticsPerRound, getPointsVariance),
+			AgentManipulations:   averageStatisticsOverRounds(statisticsPerRound, getManipulations),
+			AgentMessagesDropped: averageStatisticsOverRounds(statisticsPerRound, getMessagesDropped),
+			AgentDegree:          averageStatisticsOverRounds(statisticsPerRound, getDegree),
 		},
+		Votes:   votesPerRound,
+		Network: networkPerRound,
+	}
+}
+
+func agentManipulations(gameStates []GameStateDump) map[uuid.UUID]float64 {
+	result := make(map[uuid.UUID]float64)
+	for _, gameState := range gameStates {
+		// every agent is counted, even those that could never manipulate a vote
+		for id := range gameState.Agents {
+			if _, ok := result[id]; !ok {
+				result[id] = 0.0
+			}
+		}
+		for _, voteAnalysis := range gameState.VoteAnalyses {
+			for _, id := range voteAnalysis.Manipulators {
+				result[id]++
+			}
+		}
+	}
+	return result
+}
+
+func agentMessagesDropped(gameStates []GameStateDump) map[uuid.UUID]float64 {
+	result := make(map[uuid.UUID]float64)
+	// the count only grows over a game, so the last value seen is the one at the end of the round
+	for _, gameState := range gameStates {
+		for id, agent := range gameState.Agents {
+			result[id] = float64(agent.MessagesDropped)
+		}
+	}
+	return result
+}
+
+// the messages delivered over the game states, in order
+func gameMessages(gameStates []GameStateDump) []MessageRecord {
+	messages := make([]MessageRecord, 0)
+	for _, gameState := range gameStates {
+		messages = append(messages, gameState.Messages...)
+	}
+	return messages
+}
+
+// the agents each agent sent messages to or got messages from
+func messagingNeighbours(messages []MessageRecord) map[uuid.UUID]map[uuid.UUID]bool {
+	neighbours := make(map[uuid.UUID]map[uuid.UUID]bool)
+	link := func(a uuid.UUID, b uuid.UUID) {
+		if _, ok := neighbours[a]; !ok {
+			neighbours[a] = make(map[uuid.UUID]bool)
+		}
+		neighbours[a][b] = true
+	}
+	for _, message := range messages {
+		link(message.Sender, message.Recipient)
+		link(message.Recipient, message.Sender)
 	}
+	return neighbours
+}
+
+func agentDegree(gameStates []GameStateDump) map[uuid.UUID]float64 {
+	result := make(map[uuid.UUID]float64)
+	// every agent is counted, even those that never talked to anyone
+	for _, gameState := range gameStates {
+		for id := range gameState.Agents {
+			result[id] = 0.0
+		}
+	}
+	for id, agentNeighbours := range messagingNeighbours(gameMessages(gameStates)) {
+		result[id] = float64(len(agentNeighbours))
+	}
+	return result
+}
+
+func networkStatistics(gameStates []GameStateDump) NetworkStatistics {
+	var statistics NetworkStatistics
+	messages := gameMessages(gameStates)
+	statistics.Messages = len(messages)
+
+	// the team of an agent is the package of its class
+	teams := make(map[uuid.UUID]string)
+	for _, gameState := range gameStates {
+		for id, agent := range gameState.Agents {
+			teams[id], _, _ = strings.Cut(agent.Class, ".")
+		}
+	}
+	type link struct{ from, to uuid.UUID }
+	links := make(map[link]bool)
+	checked := 0
+	for _, message := range messages {
+		links[link{message.Sender, message.Recipient}] = true
+		if teams[message.Sender] == teams[message.Recipient] {
+			statistics.InTeamShare++
+		}
+		if message.Honesty != objects.ClaimUnverifiable {
+			checked++
+			if message.Honesty == objects.ClaimTrue {
+				statistics.HonestyRate++
+			}
+		}
+	}
+	statistics.Links = len(links)
+	for l := range links {
+		if links[link{l.to, l.from}] {
+			statistics.Reciprocity++
+		}
+	}
+
+	neighbours := messagingNeighbours(messages)
+	clustered := 0
+	for _, agentNeighbours := range neighbours {
+		statistics.AverageDegree += float64(len(agentNeighbours))
+		if len(agentNeighbours) < 2 {
+			continue
+		}
+		// the share of the pairs of neighbours of the agent that talked to each other
+		connected := 0
+		for a := range agentNeighbours {
+			for b := range agentNeighbours {
+				if a.String() < b.String() && neighbours[a][b] {
+					connected++
+				}
+			}
+		}
+		pairs := len(agentNeighbours) * (len(agentNeighbours) - 1) / 2
+		statistics.Clustering += float64(connected) / float64(pairs)
+		clustered++
+	}
+
+	if len(neighbours) != 0 {
+		statistics.AverageDegree /= float64(len(neighbours))
+	}
+	if statistics.Links != 0 {
+		statistics.Reciprocity /= float64(statistics.Links)
+	}
+	if clustered != 0 {
+		statistics.Clustering /= float64(clustered)
+	}
+	if statistics.Messages != 0 {
+		statistics.InTeamShare /= float64(statistics.Messages)
+	}
+	if checked != 0 {
+		statistics.HonestyRate /= float64(checked)
+	}
+	return statistics
+}
+
+func voteStatistics(gameStates []GameStateDump) VoteStatistics {
+	var statistics VoteStatistics
+	withCondorcetWinner := 0
+	for _, gameState := range gameStates {
+		for _, voteAnalysis := range gameState.VoteAnalyses {
+			statistics.Votes++
+			statistics.AverageMargin += voteAnalysis.Margin
+			if len(voteAnalysis.Manipulators) != 0 {
+				statistics.ManipulableShare++
+			}
+			if len(voteAnalysis.CoalitionCandidates) != 0 {
+				statistics.CoalitionShare++
+			}
+			if voteAnalysis.CondorcetWinner != uuid.Nil {
+				withCondorcetWinner++
+				if voteAnalysis.CondorcetWinner == voteAnalysis.Winner {
+					statistics.CondorcetWinnerShare++
+				}
+			}
+		}
+	}
+	if statistics.Votes != 0 {
+		statistics.AverageMargin /= float64(statistics.Votes)
+		statistics.ManipulableShare /= float64(statistics.Votes)
+		statistics.CoalitionShare /= float64(statistics.Votes)
+	}
+	if withCondorcetWinner != 0 {
+		statistics.CondorcetWinnerShare /= float64(withCondorcetWinner)
+	}
+	return statistics
 }
 
 func agentLifetime(gameStates []GameStateDump) map[uuid.UUID]float64 {
@@ -163,6 +381,47 @@ func (gs *GameStatistics) ToSpreadsheet() *xlsx.File {
 	writeSheet("Energy Variance", getEnergyVariance)
 	writeSheet("Points Average", getPointsAverage)
 	writeSheet("Points Variance", getPointsVariance)
+	writeSheet("Manipulations", getManipulations)
+	writeSheet("Dropped Messages", getMessagesDropped)
+	writeSheet("Degree", getDegree)
+
+	sheet, err := workbook.AddSheet("Votes")
+	if err != nil {
+		panic(err)
+	}
+	headerRow := sheet.AddRow()
+	for i, header := range []string{"Round", "Votes", "Manipulable Share", "Coalition Share", "Condorcet Winner Share", "Average Margin"} {
+		headerRow.GetCell(i).SetString(header)
+	}
+	for i, votes := range gs.Votes {
+		row := sheet.AddRow()
+		row.GetCell(0).SetValue(i + 1)
+		row.GetCell(1).SetValue(votes.Votes)
+		row.GetCell(2).SetValue(votes.ManipulableShare)
+		row.GetCell(3).SetValue(votes.CoalitionShare)
+		row.GetCell(4).SetValue(votes.CondorcetWinnerShare)
+		row.GetCell(5).SetValue(votes.AverageMargin)
+	}
+
+	sheet, err = workbook.AddSheet("Network")
+	if err != nil {
+		panic(err)
+	}
+	headerRow = sheet.AddRow()
+	for i, header := range []string{"Round", "Messages", "Links", "Average Degree", "Reciprocity", "Clustering", "In Team Share", "Honesty Rate"} {
+		headerRow.GetCell(i).SetString(header)
+	}
+	for i, network := range gs.Network {
+		row := sheet.AddRow()
+		row.GetCell(0).SetValue(i + 1)
+		row.GetCell(1).SetValue(network.Messages)
+		row.GetCell(2).SetValue(network.Links)
+		row.GetCell(3).SetValue(network.AverageDegree)
+		row.GetCell(4).SetValue(network.Reciprocity)
+		row.GetCell(5).SetValue(network.Clustering)
+		row.GetCell(6).SetValue(network.InTeamShare)
+		row.GetCell(7).SetValue(network.HonestyRate)
+	}
 
 	return workbook
 }
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// returns the IDs of the riders of a bike, in a fixed order
+func riderIDs(bike objects.IMegaBike) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(bike.GetAgents()))
+	for _, agent := range bike.GetAgents() {
+		ids = append(ids, agent.GetID())
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
+
+// returns the rider that takes over from the current ruler of a rotating leadership bike
+func nextRotatingRuler(bike objects.IMegaBike) uuid.UUID {
+	ids := riderIDs(bike)
+	for _, id := range ids {
+		if id.String() > bike.GetRuler().String() {
+			return id
+		}
+	}
+	return ids[0]
+}
+
+// draws the ruler of a sortition bike by lot
+func drawSortitionRuler(bike objects.IMegaBike) uuid.UUID {
+	ids := riderIDs(bike)
+	return ids[rand.Intn(len(ids))]
+}
+
+// elects the candidates with the most leader votes from the riders to the council of a bike
+func (s *Server) electCouncil(bike objects.IMegaBike, candidates []uuid.UUID) []uuid.UUID {
+	ids := riderIDs(bike)
+	weights := make(map[uuid.UUID]float64, len(ids))
+	for _, id := range ids {
+		weights[id] = 1.0
+	}
+	weights = s.addPurchasedVoteWeight(weights)
+	support := make(map[uuid.UUID]float64, len(candidates))
+	for _, agent := range bike.GetAgents() {
+		for candidate, vote := range agent.VoteLeader() {
+			if slices.Contains(candidates, candidate) {
+				support[candidate] += vote * weights[agent.GetID()]
+			}
+		}
+	}
+	council := slices.Clone(candidates)
+	sort.SliceStable(council, func(i, j int) bool {
+		return support[council[i]] > support[council[j]]
+	})
+	return council[:min(utils.CouncilSize, len(council))]
+}
+
+// checks whether a bike has lost (or never had) the ruler or the council its governance requires
+func (s *Server) rulerMissing(bike objects.IMegaBike) bool {
+	ids := riderIDs(bike)
+	if len(ids) == 0 {
+		return false
+	}
+	rulers, ruled := s.governanceProtocol(bike).InPower(bike)
+	if !ruled {
+		return false
+	}
+	if len(rulers) == 0 {
+		return true
+	}
+	for _, ruler := range rulers {
+		if !slices.Contains(ids, ruler) {
+			return true
+		}
+	}
+	return false
+}
+
+// appoints the ruler (or the council) of a bike according to its governance
+func (s *Server) appointRuler(bike objects.IMegaBike) {
+	bike.SetCouncil(make([]uuid.UUID, 0))
+	delete(s.councilElections, bike.GetID())
+	if len(bike.GetAgents()) == 0 {
+		s.setRuler(bike, uuid.Nil)
+		return
+	}
+	s.governanceProtocol(bike).Succession(s, bike)
+}
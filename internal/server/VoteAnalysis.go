@@ -0,0 +1,60 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/common/voting/analysis"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// the analysis of a vote held on a bike
+type VoteAnalysis struct {
+	Round  int              `json:"round"`
+	BikeID uuid.UUID        `json:"bike_id"`
+	Action utils.Action     `json:"action"`
+	Method utils.VoteMethod `json:"method"`
+	analysis.Report
+}
+
+func (s *Server) SetVoteAnalysis(enabled bool) {
+	s.voteAnalysis = enabled
+}
+
+func (s *Server) GetVoteAnalyses() []VoteAnalysis {
+	return slices.Clone(s.voteAnalyses)
+}
+
+// returns the analyses of the votes held in the current round
+func (s *Server) getRoundVoteAnalyses() []VoteAnalysis {
+	analyses := make([]VoteAnalysis, 0)
+	for _, voteAnalysis := range s.voteAnalyses {
+		if voteAnalysis.Round == s.round {
+			analyses = append(analyses, voteAnalysis)
+		}
+	}
+	return analyses
+}
+
+// analyses a vote for strategic voting, if enabled, against the winner the vote elected. Every replay of the vote
+// gets its own copy of the tie breaker so that the analysis doesn't change the random draws of the game
+func (s *Server) analyseVote(bikeID uuid.UUID, action utils.Action, method utils.VoteMethod, ballots map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64, tieBreaker *voting.TieBreaker, elected uuid.UUID) {
+	if !s.voteAnalysis {
+		return
+	}
+	winner := func(voters map[uuid.UUID]voting.IVoter, weights map[uuid.UUID]float64) (uuid.UUID, error) {
+		replay := voting.NewTieBreaker(tieBreaker.Rule, nil)
+		replay.Incumbent = tieBreaker.Incumbent
+		replay.Seniority = tieBreaker.Seniority
+		return voting.WinnerFromDistWithMethod(voters, weights, method, replay)
+	}
+	report := analysis.AnalyseOutcome(ballots, weights, elected, winner)
+	s.voteAnalyses = append(s.voteAnalyses, VoteAnalysis{
+		Round:  s.round,
+		BikeID: bikeID,
+		Action: action,
+		Method: method,
+		Report: report,
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// the decisions of a bike that are taken with a voting method
+var votedDecisions = []utils.Action{utils.Direction, utils.Election}
+
+// returns the voting method most riders of a bike want for a decision and the share of the riders that want it.
+// ties go to the current method, then to the method listed first
+func preferredVoteMethod(bike objects.IMegaBike, action utils.Action) (utils.VoteMethod, float64) {
+	agents := bike.GetAgents()
+	counts := make(map[utils.VoteMethod]int)
+	for _, agent := range agents {
+		method := agent.DecideVoteMethod(action)
+		if method >= 0 && method < utils.NumOfVoteMethods {
+			counts[method]++
+		}
+	}
+	preferred := bike.GetVoteMethod(action)
+	for method := utils.VoteMethod(0); method < utils.NumOfVoteMethods; method++ {
+		if counts[method] > counts[preferred] {
+			preferred = method
+		}
+	}
+	if len(agents) == 0 {
+		return preferred, 0.0
+	}
+	return preferred, float64(counts[preferred]) / float64(len(agents))
+}
+
+// the founding riders of a bike pick the voting method of each decision
+func (s *Server) foundVoteMethods(bike objects.IMegaBike) {
+	for _, action := range votedDecisions {
+		method, _ := preferredVoteMethod(bike, action)
+		bike.SetVoteMethod(action, method)
+	}
+}
+
+// the riders of a bike can switch the voting method of a decision if enough of them want to
+func (s *Server) RunVoteMethodVote(bike objects.IMegaBike) {
+	for _, action := range votedDecisions {
+		current := bike.GetVoteMethod(action)
+		method, share := preferredVoteMethod(bike, action)
+		if method == current || share < utils.VoteMethodMajority-utils.Epsilon {
+			continue
+		}
+		bike.SetVoteMethod(action, method)
+		s.logEvent(VoteMethodChangeEvent, bike.GetID(), fmt.Sprintf("switched from %s to %s with %.2f of the vote", current, method, share))
+	}
+}
+
+// returns a tie breaker following utils.TieBreakRule. The incumbent is the current choice of the bike,
+// the riders are listed in the order they joined the bike (empty when the candidates aren't riders)
+func (s *Server) tieBreaker(incumbent uuid.UUID, riders []objects.IBaseBiker) *voting.TieBreaker {
+	tieBreaker := voting.NewTieBreaker(utils.TieBreakRule, s.tieBreakRand)
+	tieBreaker.Incumbent = incumbent
+	for _, rider := range riders {
+		tieBreaker.Seniority = append(tieBreaker.Seniority, rider.GetID())
+	}
+	return tieBreaker
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// the riders of a democracy vote with the weights given by the weighting policy of their bike
+func democraticWeights(s *Server, bike objects.IMegaBike, action utils.Action) map[uuid.UUID]float64 {
+	return s.policyWeights(bike)
+}
+
+// returns the weight the weighting policy of the bike gives the vote of each rider. The weights are scaled so that
+// they add up to the number of riders, as they would if every vote counted the same. If the policy gives no rider
+// any weight (e.g. nobody has pedalled yet), every vote counts the same
+func (s *Server) policyWeights(bike objects.IMegaBike) map[uuid.UUID]float64 {
+	agents := bike.GetAgents()
+	merits := make(map[uuid.UUID]float64, len(agents))
+	for _, agent := range agents {
+		var merit float64
+		switch bike.GetWeightingPolicy() {
+		case utils.ReputationWeighting:
+			merit = averageReputation(agent, agents)
+		case utils.SeniorityWeighting:
+			merit = float64(s.round - s.boardingRounds[agent.GetID()] + 1)
+		case utils.ContributionWeighting:
+			merit = s.pedalledEnergy[agent.GetID()]
+		default:
+			merit = 1.0
+		}
+		if merit < 0 || math.IsNaN(merit) || math.IsInf(merit, 0) {
+			merit = 0.0
+		}
+		merits[agent.GetID()] = merit
+	}
+
+	total := 0.0
+	for _, merit := range merits {
+		total += merit
+	}
+	weights := make(map[uuid.UUID]float64, len(agents))
+	for id, merit := range merits {
+		if total > 0 {
+			weights[id] = merit * float64(len(agents)) / total
+		} else {
+			weights[id] = 1.0
+		}
+	}
+	return weights
+}
+
+// the average reputation the fellow riders of an agent give it
+func averageReputation(agent objects.IBaseBiker, riders []objects.IBaseBiker) float64 {
+	total, count := 0.0, 0
+	for _, rider := range riders {
+		if rider.GetID() == agent.GetID() {
+			continue
+		}
+		total += rider.GetReputation()[agent.GetID()]
+		count++
+	}
+	if count == 0 {
+		return 0.0
+	}
+	return total / float64(count)
+}
@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type AuditingAgent struct {
+	*objects.BaseBiker
+	requests []objects.AuditRequest
+	results  []objects.AuditResultMessage
+}
+
+func (a *AuditingAgent) DecideAudits() []objects.AuditRequest {
+	requests := a.requests
+	a.requests = nil
+	return requests
+}
+
+func (a *AuditingAgent) HandleAuditResultMessage(msg objects.AuditResultMessage) {
+	a.results = append(a.results, msg)
+}
+
+func setupAudit(s server.IBaseBikerServer) (objects.IMegaBike, *AuditingAgent, *ShoppingAgent) {
+	auditor := &AuditingAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}
+	subject := NewShoppingAgent(0)
+	bike := seatShoppers(s, subject)
+	s.AddAgent(auditor)
+	auditor.SetBike(bike.GetID())
+	s.AddAgentToBike(auditor)
+	s.UpdateGameStates()
+	return bike, auditor, subject
+}
+
+func TestAuditOfForces(t *testing.T) {
+	s := server.Initialize(0)
+	_, auditor, subject := setupAudit(s)
+	s.RunActionProcess()
+	forces := subject.GetForces()
+	lie := forces
+	lie.Pedal += 0.5
+	auditor.requests = []objects.AuditRequest{
+		{Claim: objects.ForcesClaim, Subject: subject.GetID(), Forces: forces},
+		{Claim: objects.ForcesClaim, Subject: subject.GetID(), Forces: lie},
+		{Claim: objects.ForcesClaim, Subject: uuid.New(), Forces: forces},
+	}
+	energy := auditor.GetEnergyLevel()
+
+	s.RunAudits()
+
+	assert.Len(t, auditor.results, 3)
+	assert.Equal(t, objects.ClaimTrue, auditor.results[0].Outcome)
+	assert.Equal(t, objects.ClaimFalse, auditor.results[1].Outcome)
+	assert.Equal(t, objects.ClaimUnverifiable, auditor.results[2].Outcome)
+	assert.Equal(t, subject.GetID(), auditor.results[0].Request.Subject)
+	assert.InDelta(t, energy-3*utils.AuditCost, auditor.GetEnergyLevel(), 1e-9)
+}
+
+func TestAuditOfDirectionVote(t *testing.T) {
+	s := server.Initialize(0)
+	bike, auditor, subject := setupAudit(s)
+	claim := objects.AuditRequest{Claim: objects.DirectionVoteClaim, Subject: subject.GetID()}
+	assert.Equal(t, objects.ClaimUnverifiable, s.Audit(claim))
+
+	weights := make(map[uuid.UUID]float64)
+	for _, agent := range bike.GetAgents() {
+		weights[agent.GetID()] = 1.0
+	}
+	direction := s.RunDemocraticAction(bike, weights)
+
+	// the subject claims it voted for a lootbox it didn't vote for
+	for id := range s.GetLootBoxes() {
+		if id != direction {
+			claim.VoteMap = map[uuid.UUID]float64{id: 1.0}
+			break
+		}
+	}
+	assert.Equal(t, objects.ClaimFalse, s.Audit(claim))
+	proposals := map[uuid.UUID]uuid.UUID{
+		subject.GetID(): subject.ProposeDirection(),
+		auditor.GetID(): auditor.ProposeDirection(),
+	}
+	claim.VoteMap = subject.FinalDirectionVote(proposals).GetVotes()
+	assert.Equal(t, objects.ClaimTrue, s.Audit(claim))
+}
+
+func TestAuditOfARoundWithoutRecords(t *testing.T) {
+	s := server.Initialize(0)
+	bike, _, subject := setupAudit(s)
+	s.RunActionProcess()
+	weights := make(map[uuid.UUID]float64)
+	for _, agent := range bike.GetAgents() {
+		weights[agent.GetID()] = 1.0
+	}
+	s.RunDemocraticAction(bike, weights)
+	forces := objects.AuditRequest{Claim: objects.ForcesClaim, Subject: subject.GetID(), Forces: subject.GetForces()}
+	assert.Equal(t, objects.ClaimTrue, s.Audit(forces))
+
+	// the same claims about a round that hasn't been played yet can't be checked
+	forces.Round = 1
+	assert.Equal(t, objects.ClaimUnverifiable, s.Audit(forces))
+	vote := objects.AuditRequest{Claim: objects.DirectionVoteClaim, Subject: subject.GetID(), Round: 1}
+	assert.Equal(t, objects.ClaimUnverifiable, s.Audit(vote))
+}
@@ -0,0 +1,157 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// proposes, votes and allocates for things that don't exist
+type CheatingAgent struct {
+	*ShoppingAgent
+}
+
+func (a *CheatingAgent) ProposeDirection() uuid.UUID {
+	return uuid.New()
+}
+
+func (a *CheatingAgent) FinalDirectionVote(proposals map[uuid.UUID]uuid.UUID) voting.LootboxVoteMap {
+	return voting.LootboxVoteMap{uuid.Nil: 1.0}
+}
+
+func (a *CheatingAgent) DecideAllocation() voting.IdVoteMap {
+	return voting.IdVoteMap{uuid.New(): 0.5, a.GetID(): 0.5}
+}
+
+func (a *CheatingAgent) DecideWeights(action utils.Action) map[uuid.UUID]float64 {
+	return map[uuid.UUID]float64{a.GetID(): 1.0, uuid.New(): 1.0}
+}
+
+func (a *CheatingAgent) VoteForKickout() map[uuid.UUID]int {
+	return map[uuid.UUID]int{uuid.New(): 100}
+}
+
+func (a *CheatingAgent) DecideKickOut() []uuid.UUID {
+	return []uuid.UUID{uuid.New()}
+}
+
+func (a *CheatingAgent) DecideDictatorAllocation() voting.IdVoteMap {
+	return voting.IdVoteMap{uuid.New(): 0.5, a.GetID(): 0.5}
+}
+
+func setupCheating(s server.IBaseBikerServer, governance utils.Governance) (objects.IMegaBike, *CheatingAgent, *ShoppingAgent) {
+	cheater := &CheatingAgent{ShoppingAgent: NewShoppingAgent(0)}
+	honest := NewShoppingAgent(0)
+	bike := seatShoppers(s, honest)
+	s.AddAgent(cheater)
+	cheater.SetBike(bike.GetID())
+	s.AddAgentToBike(cheater)
+	bike.SetGovernance(governance)
+	s.UpdateGameStates()
+	return bike, cheater, honest
+}
+
+func countEvents(s server.IBaseBikerServer, eventType server.EventType) int {
+	count := 0
+	for _, event := range s.GetEvents() {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestInvalidDirectionVotesArePenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, cheater, honest := setupCheating(s, utils.Democracy)
+	weights := map[uuid.UUID]float64{cheater.GetID(): 1.0, honest.GetID(): 1.0}
+
+	direction := s.RunDemocraticAction(bike, weights)
+
+	assert.Contains(t, s.GetLootBoxes(), direction)
+	// once for the proposal and once for the vote
+	assert.InDelta(t, 1.0-2*utils.InvalidBallotPenalty, cheater.GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 1.0, honest.GetEnergyLevel(), utils.Epsilon)
+	assert.Equal(t, 2, countEvents(s, server.InvalidBallotEvent))
+}
+
+func TestInvalidAllocationsArePenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, cheater, honest := setupCheating(s, utils.Democracy)
+
+	allocation := protocolOf(t, s, utils.Democracy).Allocation(s.(*server.Server), bike)
+
+	assert.InDelta(t, 1.0-utils.InvalidBallotPenalty, cheater.GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 1.0, honest.GetEnergyLevel(), utils.Epsilon)
+	total := 0.0
+	for id, share := range allocation {
+		assert.True(t, id == cheater.GetID() || id == honest.GetID())
+		total += share
+	}
+	assert.InDelta(t, 1.0, total, utils.Epsilon)
+}
+
+func TestLeaderWeightingOffBikeAgentIsPenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, cheater, _ := setupCheating(s, utils.Leadership)
+	bike.SetRuler(cheater.GetID())
+
+	assert.NotPanics(t, func() {
+		protocolOf(t, s, utils.Leadership).Allocation(s.(*server.Server), bike)
+	})
+	// once for the weights and once for the allocation
+	assert.InDelta(t, 1.0-2*utils.InvalidBallotPenalty, cheater.GetEnergyLevel(), utils.Epsilon)
+	assert.Equal(t, 2, countEvents(s, server.InvalidBallotEvent))
+}
+
+func TestKickoutVotesForUnknownAgentsAreIgnored(t *testing.T) {
+	s := server.Initialize(0)
+	bike, _, _ := setupCheating(s, utils.Democracy)
+
+	var kicked []uuid.UUID
+	assert.NotPanics(t, func() {
+		kicked = s.HandleKickoutProcess()
+	})
+	assert.Empty(t, kicked)
+	assert.Len(t, bike.GetAgents(), 2)
+}
+
+func TestDictatorKickingOutUnknownAgentIsPenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, cheater, _ := setupCheating(s, utils.Dictatorship)
+	bike.SetRuler(cheater.GetID())
+
+	var kicked []uuid.UUID
+	assert.NotPanics(t, func() {
+		kicked = s.HandleKickoutProcess()
+	})
+	assert.Empty(t, kicked)
+	assert.InDelta(t, 1.0-utils.InvalidBallotPenalty, cheater.GetEnergyLevel(), utils.Epsilon)
+	assert.Equal(t, 1, countEvents(s, server.InvalidBallotEvent))
+}
+
+func TestDictatorAllocatingToUnknownAgentIsPenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, cheater, _ := setupCheating(s, utils.Dictatorship)
+	bike.SetRuler(cheater.GetID())
+
+	allocation := protocolOf(t, s, utils.Dictatorship).Allocation(s.(*server.Server), bike)
+
+	assert.Equal(t, voting.IdVoteMap{cheater.GetID(): 0.5}, allocation)
+	assert.InDelta(t, 1.0-utils.InvalidBallotPenalty, cheater.GetEnergyLevel(), utils.Epsilon)
+
+	// the loot is only shared between riders
+	var lootbox objects.ILootBox
+	for _, lootbox = range s.GetLootBoxes() {
+		break
+	}
+	state := bike.GetPhysicalState()
+	state.Position = lootbox.GetPosition()
+	bike.SetPhysicalState(state)
+	assert.NotPanics(t, s.LootboxCheckAndDistributions)
+}
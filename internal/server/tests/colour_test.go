@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingColourPolicy(t *testing.T) {
+	s := server.Initialize(1)
+	s.SetColourPolicy(utils.RotatingColour)
+	agent := NewShoppingAgent(0)
+	seatShoppers(s, agent)
+
+	s.UpdateAgentColour(agent)
+	s.UpdateAgentColour(agent)
+
+	assert.Equal(t, utils.Blue, agent.GetColour())
+	assert.Equal(t, []utils.Colour{utils.Red, utils.Green, utils.Blue}, s.GetColourHistory(agent))
+}
+
+func TestNearestColourPolicy(t *testing.T) {
+	s := server.Initialize(1)
+	s.SetColourPolicy(utils.NearestColour)
+	agent := NewShoppingAgent(0)
+	bike := seatShoppers(s, agent)
+
+	// move one lootbox next to the bike and every other one far away
+	var nearest objects.ILootBox
+	for _, lootBox := range s.GetLootBoxes() {
+		state := lootBox.GetPhysicalState()
+		if nearest == nil {
+			nearest = lootBox
+			state.Position = bike.GetPosition()
+		} else {
+			state.Position = utils.Coordinates{X: -1000.0, Y: -1000.0}
+		}
+		lootBox.SetPhysicalState(state)
+	}
+	agent.SetColour((nearest.GetColour() + 1) % utils.NumOfColours)
+
+	s.UpdateAgentColour(agent)
+
+	assert.Equal(t, nearest.GetColour(), agent.GetColour())
+}
+
+func TestColourHistoryInDump(t *testing.T) {
+	s := server.Initialize(1)
+	agent := NewShoppingAgent(utils.ColourChangeCost)
+	seatShoppers(s, agent)
+
+	// agents that never changed colour only have their current colour
+	assert.Equal(t, []string{"red"}, s.NewGameStateDump(0).Agents[agent.GetID()].ColourHistory)
+
+	agent.purchases = append(agent.purchases, objects.PointsPurchase{Type: objects.ColourChangePurchase, Colour: utils.White})
+	s.RunPointsPurchases()
+	s.UpdateAgentColour(agent)
+
+	history := s.NewGameStateDump(0).Agents[agent.GetID()].ColourHistory
+	assert.GreaterOrEqual(t, len(history), 2)
+	assert.Equal(t, []string{"red", "white"}, history[:2])
+	assert.Equal(t, agent.GetColour().String(), history[len(history)-1])
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ConstitutionalAgent struct {
+	*ShoppingAgent
+	preference utils.Governance
+	ballot     voting.GovernanceVote // cast instead of a vote for the preference, if set
+}
+
+func (a *ConstitutionalAgent) VoteGovernance() voting.GovernanceVote {
+	if a.ballot != nil {
+		return a.ballot
+	}
+	return voting.GovernanceVote{a.preference: 1.0}
+}
+
+// seats riders on a democratic bike, the first supporters of them prefer the given governance
+func setupConstitutionalVote(s server.IBaseBikerServer, riders int, supporters int, preference utils.Governance) (objects.IMegaBike, []*ConstitutionalAgent) {
+	agents := make([]*ShoppingAgent, riders)
+	for i := range agents {
+		agents[i] = NewShoppingAgent(0)
+	}
+	bike := seatShoppers(s)
+	constitutionals := make([]*ConstitutionalAgent, riders)
+	for i, agent := range agents {
+		constitutionals[i] = &ConstitutionalAgent{ShoppingAgent: agent, preference: utils.Democracy}
+		if i < supporters {
+			constitutionals[i].preference = preference
+		}
+		s.AddAgent(constitutionals[i])
+		constitutionals[i].SetBike(bike.GetID())
+		s.AddAgentToBike(constitutionals[i])
+	}
+	bike.SetGovernance(utils.Democracy)
+	s.UpdateGameStates()
+	return bike, constitutionals
+}
+
+func TestConstitutionalVoteChangesGovernance(t *testing.T) {
+	s := server.Initialize(0)
+	bike, _ := setupConstitutionalVote(s, 6, 5, utils.Leadership)
+
+	s.RunConstitutionalVote(bike)
+
+	assert.Equal(t, utils.Leadership, bike.GetGovernance())
+	assert.NotEqual(t, uuid.Nil, bike.GetRuler())
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.GovernanceChangeEvent, events[0].Type)
+	assert.Equal(t, bike.GetID(), events[0].BikeID)
+}
+
+func TestConstitutionalVoteNeedsSupermajority(t *testing.T) {
+	s := server.Initialize(0)
+	bike, _ := setupConstitutionalVote(s, 8, 5, utils.Dictatorship)
+
+	s.RunConstitutionalVote(bike)
+
+	assert.Equal(t, utils.Democracy, bike.GetGovernance())
+	assert.Equal(t, uuid.Nil, bike.GetRuler())
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.ConstitutionalVoteEvent, events[0].Type)
+}
+
+func TestOneRiderCantOutvoteTheBikeWithNegativeVotes(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupConstitutionalVote(s, 8, 0, utils.Democracy)
+	agents[0].ballot = voting.GovernanceVote{utils.Leadership: -5.0, utils.Dictatorship: 6.0}
+
+	s.RunConstitutionalVote(bike)
+
+	assert.Equal(t, utils.Democracy, bike.GetGovernance())
+	assert.Equal(t, 1, countEvents(s, server.InvalidBallotEvent))
+	assert.InDelta(t, 1.0-utils.InvalidBallotPenalty, agents[0].GetEnergyLevel(), utils.Epsilon)
+}
+
+func TestUnanimousVoteForAnUnknownGovernanceFails(t *testing.T) {
+	s := server.Initialize(0)
+	bike, _ := setupConstitutionalVote(s, 4, 4, utils.Invalid)
+
+	s.RunConstitutionalVote(bike)
+
+	assert.Equal(t, utils.Democracy, bike.GetGovernance())
+	assert.Equal(t, 4, countEvents(s, server.InvalidBallotEvent))
+	assert.Equal(t, 0, countEvents(s, server.GovernanceChangeEvent))
+}
@@ -0,0 +1,114 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type CandidateAgent struct {
+	*ShoppingAgent
+	candidate bool
+	campaigns int
+}
+
+func (a *CandidateAgent) DecideCandidacy() bool {
+	return a.candidate
+}
+
+func (a *CandidateAgent) HandleCampaignMessage(msg objects.CampaignMessage) {
+	a.campaigns++
+}
+
+func setupElection(s server.IBaseBikerServer, riders int) (objects.IMegaBike, []*CandidateAgent) {
+	bike := seatShoppers(s)
+	agents := make([]*CandidateAgent, riders)
+	for i := range agents {
+		agents[i] = &CandidateAgent{ShoppingAgent: NewShoppingAgent(0), candidate: i == 0}
+		s.AddAgent(agents[i])
+		agents[i].SetBike(bike.GetID())
+		s.AddAgentToBike(agents[i])
+	}
+	bike.SetGovernance(utils.Leadership)
+	s.UpdateGameStates()
+	return bike, agents
+}
+
+func TestOnlyCandidatesCanBeElected(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupElection(s, 4)
+
+	s.RunLeadershipElection(bike)
+
+	assert.Equal(t, agents[0].GetID(), bike.GetRuler())
+	term, ok := s.GetRulerTerm(bike.GetID())
+	assert.True(t, ok)
+	assert.Equal(t, 1, term.Terms)
+	// every other rider heard the campaign of the only candidate
+	assert.Equal(t, 0, agents[0].campaigns)
+	for _, agent := range agents[1:] {
+		assert.Equal(t, 1, agent.campaigns)
+	}
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.LeadershipElectionEvent, events[0].Type)
+}
+
+func TestCampaignMessagesAreSentLikeOtherMessages(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingLimits(utils.MessagingLimits{MessageCost: 0.01, RecipientCost: 0.005})
+	bike, agents := setupElection(s, 3)
+	energy := agents[0].GetEnergyLevel()
+
+	s.RunLeadershipElection(bike)
+
+	// the candidate pays for its campaign, which addresses every rider of the bike
+	assert.InDelta(t, energy-0.01-0.005*3, agents[0].GetEnergyLevel(), 1e-9)
+	log := s.GetMessageLog()
+	assert.Len(t, log, 2)
+	for i, record := range log {
+		assert.Equal(t, agents[0].GetID(), record.Sender)
+		assert.Equal(t, agents[i+1].GetID(), record.Recipient)
+		assert.Equal(t, utils.Campaign, record.SentPhase)
+		assert.Equal(t, utils.Campaign, record.Phase)
+		assert.Equal(t, 1, agents[i+1].campaigns)
+		assert.Len(t, agents[i+1].GetInbox(), 1)
+	}
+}
+
+func TestCampaignCountsAgainstTheQuota(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingLimits(utils.MessagingLimits{Quota: 1})
+	bike, agents := setupElection(s, 3)
+
+	s.RunLeadershipElection(bike)
+	s.RunLeadershipElection(bike)
+
+	// the second campaign is over the quota of the round
+	assert.Len(t, s.GetMessageLog(), 2)
+	assert.Equal(t, 3, s.GetDroppedMessages(agents[0].GetID()))
+	for _, agent := range agents[1:] {
+		assert.Equal(t, 1, agent.campaigns)
+	}
+}
+
+func TestLeaderTermLimit(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupElection(s, 4)
+
+	for i := 0; i < utils.LeaderTermLimit; i++ {
+		s.RunLeadershipElection(bike)
+		assert.Equal(t, agents[0].GetID(), bike.GetRuler())
+	}
+	term, _ := s.GetRulerTerm(bike.GetID())
+	assert.Equal(t, utils.LeaderTermLimit, term.Terms)
+
+	// the leader can't run again, so another rider takes over
+	s.RunLeadershipElection(bike)
+	assert.NotEqual(t, agents[0].GetID(), bike.GetRuler())
+	term, _ = s.GetRulerTerm(bike.GetID())
+	assert.Equal(t, 1, term.Terms)
+}
@@ -0,0 +1,204 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type TradingAgent struct {
+	*objects.BaseBiker
+	offers      []objects.EnergyTransferOffer
+	acceptLoans bool
+}
+
+func NewTradingAgent() *TradingAgent {
+	return &TradingAgent{
+		BaseBiker: objects.GetBaseBiker(utils.GenerateRandomColour(), uuid.New()),
+		offers:    make([]objects.EnergyTransferOffer, 0),
+	}
+}
+
+func (a *TradingAgent) DecideEnergyTransfers() []objects.EnergyTransferOffer {
+	offers := a.offers
+	a.offers = make([]objects.EnergyTransferOffer, 0)
+	return offers
+}
+
+func (a *TradingAgent) DecideEnergyTransferResponse(offer objects.EnergyTransferOffer) bool {
+	return !offer.Loan || a.acceptLoans
+}
+
+func setupTraders(s server.IBaseBikerServer) (*TradingAgent, *TradingAgent) {
+	giver, receiver := NewTradingAgent(), NewTradingAgent()
+	s.AddAgent(giver)
+	s.AddAgent(receiver)
+	giver.UpdateEnergyLevel(-0.2)
+	receiver.UpdateEnergyLevel(-0.6)
+	return giver, receiver
+}
+
+func TestEnergyGift(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.3})
+
+	s.RunEnergyTransfers()
+
+	assert.InDelta(t, 0.5, giver.GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 0.7, receiver.GetEnergyLevel(), utils.Epsilon)
+	ledger := s.GetEnergyLedger()
+	assert.Len(t, ledger, 1)
+	assert.Equal(t, objects.GiftTransfer, ledger[0].Type)
+	assert.Empty(t, s.GetOutstandingLoans())
+}
+
+func TestInvalidEnergyTransfersAreIgnored(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	giver.offers = append(giver.offers,
+		objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 2.0},             // more than the giver has
+		objects.EnergyTransferOffer{From: receiver.GetID(), To: giver.GetID(), Amount: 0.1},             // on behalf of someone else
+		objects.EnergyTransferOffer{From: giver.GetID(), To: uuid.New(), Amount: 0.1},                   // to an unknown agent
+		objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.1, Loan: true}, // loan with no term
+	)
+
+	s.RunEnergyTransfers()
+
+	assert.InDelta(t, 0.8, giver.GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 0.4, receiver.GetEnergyLevel(), utils.Epsilon)
+	assert.Empty(t, s.GetEnergyLedger())
+}
+
+func TestEnergyLoanIsRepaidWithInterest(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.2, Loan: true, InterestRate: 0.5, Term: 1})
+
+	// loans are rejected unless the receiver is willing to borrow
+	s.RunEnergyTransfers()
+	assert.Empty(t, s.GetOutstandingLoans())
+
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.2, Loan: true, InterestRate: 0.5, Term: 1})
+	receiver.acceptLoans = true
+	s.RunEnergyTransfers()
+	loans := s.GetOutstandingLoans()
+	assert.Len(t, loans, 1)
+	assert.Equal(t, receiver.GetID(), loans[0].Borrower)
+	assert.InDelta(t, 0.3, loans[0].AmountOwed(), utils.Epsilon)
+
+	// the loan is repaid at the start of the next round
+	gs := s.NewGameStateDump(0)
+	for _, agent := range s.GetAgentMap() {
+		agent.UpdateGameState(gs)
+	}
+	s.FoundingInstitutions()
+	s.(*server.Server).RunRoundLoop()
+	assert.Empty(t, s.GetOutstandingLoans())
+	repaid := false
+	for _, transaction := range s.GetEnergyLedger() {
+		if transaction.Type == objects.LoanRepayment && transaction.From == receiver.GetID() {
+			repaid = true
+			assert.InDelta(t, 0.3, transaction.Amount, utils.Epsilon)
+		}
+	}
+	assert.True(t, repaid, "loan should have been repaid")
+}
+
+func TestEnergyLedgerRecordsTheEnergyCredited(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.8})
+
+	s.RunEnergyTransfers()
+
+	// the giver keeps the energy above the maximum level of the receiver
+	assert.InDelta(t, 0.2, giver.GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 1.0, receiver.GetEnergyLevel(), utils.Epsilon)
+	ledger := s.GetEnergyLedger()
+	assert.Len(t, ledger, 1)
+	assert.InDelta(t, 0.6, ledger[0].Amount, utils.Epsilon)
+}
+
+func TestNothingRepaidIsOnlyADefault(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	receiver.acceptLoans = true
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.2, Loan: true, InterestRate: 0.5, Term: 1})
+	s.RunEnergyTransfers()
+	receiver.UpdateEnergyLevel(-receiver.GetEnergyLevel())
+
+	gs := s.NewGameStateDump(0)
+	for _, agent := range s.GetAgentMap() {
+		agent.UpdateGameState(gs)
+	}
+	s.FoundingInstitutions()
+	s.(*server.Server).RunRoundLoop()
+	defaulted := false
+	for _, transaction := range s.GetEnergyLedger() {
+		assert.NotEqual(t, objects.LoanRepayment, transaction.Type, "a repayment of nothing shouldn't be recorded")
+		if transaction.Type == objects.LoanDefault {
+			defaulted = true
+			assert.InDelta(t, 0.3, transaction.Amount, utils.Epsilon)
+		}
+	}
+	assert.True(t, defaulted, "loan should have defaulted")
+}
+
+func TestLoanIsOfTheEnergyCredited(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	receiver.acceptLoans = true
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.8, Loan: true, InterestRate: 0.5, Term: 1})
+
+	s.RunEnergyTransfers()
+
+	// the receiver could only take 0.6, so that is all it owes interest on
+	assert.InDelta(t, 0.2, giver.GetEnergyLevel(), utils.Epsilon)
+	loans := s.GetOutstandingLoans()
+	assert.Len(t, loans, 1)
+	assert.InDelta(t, 0.6, loans[0].Principal, utils.Epsilon)
+	assert.InDelta(t, 0.9, loans[0].AmountOwed(), utils.Epsilon)
+}
+
+func TestLoansAreCollectedInIDOrder(t *testing.T) {
+	s := server.Initialize(1)
+	giver, receiver := setupTraders(s)
+	lender := NewTradingAgent()
+	s.AddAgent(lender)
+	receiver.acceptLoans = true
+	giver.offers = append(giver.offers, objects.EnergyTransferOffer{From: giver.GetID(), To: receiver.GetID(), Amount: 0.1, Loan: true, Term: 1})
+	lender.offers = append(lender.offers, objects.EnergyTransferOffer{From: lender.GetID(), To: receiver.GetID(), Amount: 0.1, Loan: true, Term: 1})
+	s.RunEnergyTransfers()
+	loans := s.GetOutstandingLoans()
+	assert.Len(t, loans, 2)
+	first := loans[0]
+	if loans[1].ID.String() < first.ID.String() {
+		first = loans[1]
+	}
+	// the borrower can only repay one of the loans
+	receiver.UpdateEnergyLevel(0.1 - receiver.GetEnergyLevel())
+
+	gs := s.NewGameStateDump(0)
+	for _, agent := range s.GetAgentMap() {
+		agent.UpdateGameState(gs)
+	}
+	s.FoundingInstitutions()
+	s.(*server.Server).RunRoundLoop()
+
+	repaid := false
+	for _, transaction := range s.GetEnergyLedger() {
+		switch transaction.Type {
+		case objects.LoanRepayment:
+			repaid = true
+			assert.Equal(t, first.Lender, transaction.To)
+		case objects.LoanDefault:
+			assert.NotEqual(t, first.Lender, transaction.To)
+		}
+	}
+	assert.True(t, repaid, "the loan with the lowest ID should have been repaid")
+}
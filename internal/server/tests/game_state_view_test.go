@@ -0,0 +1,270 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// seats an agent on each of three bikes: the first two within the vision radius of each other, the third beyond it
+func setupViews(s server.IBaseBikerServer) []*ShoppingAgent {
+	agents := []*ShoppingAgent{NewShoppingAgent(10), NewShoppingAgent(10), NewShoppingAgent(10), NewShoppingAgent(10)}
+	bikes := make([]objects.IMegaBike, 0)
+	for _, bike := range s.GetMegaBikes() {
+		bikes = append(bikes, bike)
+	}
+	positions := []utils.Coordinates{{X: 0, Y: 0}, {X: utils.VisionRadius / 2, Y: 0}, {X: utils.VisionRadius * 2, Y: 0}}
+	seats := []int{0, 0, 1, 2}
+	for i, bike := range bikes[:3] {
+		bike.SetPhysicalState(utils.PhysicalState{Position: positions[i], Mass: utils.MassBike})
+	}
+	for i, agent := range agents {
+		s.AddAgent(agent)
+		agent.SetBike(bikes[seats[i]].GetID())
+		s.AddAgentToBike(agent)
+	}
+	agents[1].SetReputation(agents[0].GetID(), 0.8)
+	agents[2].SetReputation(agents[0].GetID(), 0.3)
+	return agents
+}
+
+func TestPartialGameStateView(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+	s.SetObservability(utils.PartialObservability)
+
+	view := s.NewGameStateView(s.NewGameStateDump(0), agents[0].GetID())
+	seen := view.GetAgents()
+
+	// fellow riders are seen in full
+	assert.Equal(t, 10, seen[agents[1].GetID()].GetPoints())
+	assert.Equal(t, 0.8, seen[agents[1].GetID()].GetReputation()[agents[0].GetID()])
+	// riders of a nearby bike are seen in part
+	assert.Contains(t, seen, agents[2].GetID())
+	assert.Equal(t, 0, seen[agents[2].GetID()].GetPoints())
+	assert.Empty(t, seen[agents[2].GetID()].GetReputation())
+	assert.Equal(t, agents[2].GetBike(), seen[agents[2].GetID()].GetBike())
+	// riders beyond the vision radius aren't seen, even on their bike
+	assert.NotContains(t, seen, agents[3].GetID())
+	assert.Empty(t, view.GetMegaBikes()[agents[3].GetBike()].GetAgents())
+	assert.Len(t, view.GetMegaBikes(), len(s.GetMegaBikes()))
+}
+
+func TestObservabilityPolicy(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+
+	s.SetObservability(utils.FullObservability)
+	s.UpdateGameStates()
+	assert.Len(t, agents[0].GetGameState().GetAgents(), len(s.GetAgentMap()))
+
+	s.SetObservability(utils.PartialObservability)
+	s.UpdateGameStates()
+	view, ok := agents[0].GetGameState().(server.GameStateView)
+	assert.True(t, ok)
+	assert.Equal(t, agents[0].GetID(), view.GetObserver())
+	assert.NotContains(t, view.GetAgents(), agents[3].GetID())
+}
+
+func TestSensorModel(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+	s.SetSensorModel(utils.SensorModel{PositionNoise: 1.0, LootBoxRange: 2.0, ForceDelay: 1})
+	s.UpdateGameStates()
+	s.RunActionProcess()
+	firstForces := agents[1].GetForces()
+
+	dump := s.NewGameStateDump(0)
+	view := s.NewGameStateView(dump, agents[0].GetID())
+	// the bike of the agent is where it is, the others are somewhere near where they are
+	ownBike := agents[0].GetBike()
+	assert.Equal(t, dump.Bikes[ownBike].GetPosition(), view.GetMegaBikes()[ownBike].GetPosition())
+	otherBike := agents[2].GetBike()
+	assert.NotEqual(t, dump.Bikes[otherBike].GetPosition(), view.GetMegaBikes()[otherBike].GetPosition())
+	assert.Equal(t, view.GetMegaBikes()[otherBike].GetPosition(), view.GetAgents()[agents[2].GetID()].GetLocation())
+	assert.NotEqual(t, dump.Audi.GetPosition(), view.GetAudi().GetPosition())
+	// the resources of the lootboxes are within the range reported
+	for id, lootBox := range view.GetLootBoxes() {
+		low, high := lootBox.GetResourceRange()
+		assert.InDelta(t, 2.0, high-low, utils.Epsilon)
+		assert.LessOrEqual(t, low, dump.LootBoxes[id].TotalResources)
+		assert.GreaterOrEqual(t, high, dump.LootBoxes[id].TotalResources)
+	}
+	// the forces of fellow riders are seen a round late
+	assert.Equal(t, utils.Forces{}, view.GetAgents()[agents[1].GetID()].GetForces())
+
+	s.UpdateGameStates()
+	s.RunActionProcess()
+	view = s.NewGameStateView(s.NewGameStateDump(0), agents[0].GetID())
+	assert.Equal(t, firstForces, view.GetAgents()[agents[1].GetID()].GetForces())
+}
+
+func TestForcesAreOnlyObservedLate(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+
+	// by default the forces of the other agents can't be read
+	s.UpdateGameStates()
+	assert.Panics(t, func() { agents[0].GetGameState().GetAgents()[agents[2].GetID()].GetForces() })
+
+	// when they are observed late, the delay holds for every agent seen, not only for fellow riders
+	s.SetSensorModel(utils.SensorModel{ForceDelay: 1})
+	s.UpdateGameStates()
+	s.RunActionProcess()
+	firstForces := agents[2].GetForces()
+	s.UpdateGameStates()
+	assert.Equal(t, utils.Forces{}, agents[0].GetGameState().GetAgents()[agents[2].GetID()].GetForces())
+
+	s.RunActionProcess()
+	s.UpdateGameStates()
+	assert.Equal(t, firstForces, agents[0].GetGameState().GetAgents()[agents[2].GetID()].GetForces())
+}
+
+// records whether it decided to stay on its bike with a view of the game
+type LeavingAgent struct {
+	*ShoppingAgent
+	decidedOnView bool
+}
+
+func (a *LeavingAgent) DecideAction() objects.BikerAction {
+	_, a.decidedOnView = a.GetGameState().(server.GameStateView)
+	return objects.Pedal
+}
+
+func TestLeavingDecisionsUseTheView(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+	agent := &LeavingAgent{ShoppingAgent: NewShoppingAgent(0)}
+	s.AddAgent(agent)
+	agent.SetBike(agents[0].GetBike())
+	s.AddAgentToBike(agent)
+	s.SetObservability(utils.PartialObservability)
+
+	s.GetLeavingDecisions(s.NewGameStateDump(0))
+
+	assert.True(t, agent.decidedOnView)
+	assert.True(t, agent.GetBikeStatus())
+}
+
+// returns the energy each agent sees every agent it observes with
+func seenEnergies(agents []*ShoppingAgent) map[uuid.UUID]map[uuid.UUID]float64 {
+	seen := make(map[uuid.UUID]map[uuid.UUID]float64, len(agents))
+	for _, agent := range agents {
+		seen[agent.GetID()] = make(map[uuid.UUID]float64)
+		for id, other := range agent.GetGameState().GetAgents() {
+			seen[agent.GetID()][id] = other.GetEnergyLevel()
+		}
+	}
+	return seen
+}
+
+// seats the agents back on their bikes after the game was reset
+func reseat(s server.IBaseBikerServer, agents []*ShoppingAgent, bikes []uuid.UUID) {
+	for i, agent := range agents {
+		agent.SetBike(bikes[i])
+		s.AddAgentToBike(agent)
+	}
+}
+
+func TestViewsAreTheSameEveryGame(t *testing.T) {
+	s := server.Initialize(0)
+	for _, agent := range s.GetAgentMap() {
+		s.RemoveAgent(agent)
+	}
+	agents := setupViews(s)
+	bikes := make([]uuid.UUID, len(agents))
+	for i, agent := range agents {
+		bikes[i] = agent.GetBike()
+	}
+	s.SetObservability(utils.PartialObservability)
+
+	s.UpdateGameStates()
+	first := seenEnergies(agents)
+
+	for game := 0; game < 10; game++ {
+		s.ResetGameState()
+		reseat(s, agents, bikes)
+		s.UpdateGameStates()
+		assert.Equal(t, first, seenEnergies(agents))
+	}
+}
+
+// remembers the energy it saw every agent with when it decided whether to leave its bike
+type ObservingAgent struct {
+	*ShoppingAgent
+	seen map[uuid.UUID]float64
+}
+
+func (a *ObservingAgent) DecideAction() objects.BikerAction {
+	a.seen = make(map[uuid.UUID]float64)
+	for id, other := range a.GetGameState().GetAgents() {
+		a.seen[id] = other.GetEnergyLevel()
+	}
+	return objects.Pedal
+}
+
+func TestLeavingDecisionsAreTakenInIDOrder(t *testing.T) {
+	s := server.Initialize(0)
+	// the other agents would ask to join random bikes at the start of each game
+	for _, agent := range s.GetAgentMap() {
+		s.RemoveAgent(agent)
+	}
+	agents := make([]*ObservingAgent, 0)
+	bikes := make([]uuid.UUID, 0)
+	for _, seated := range setupViews(s) {
+		s.RemoveAgent(seated)
+		agent := &ObservingAgent{ShoppingAgent: NewShoppingAgent(0)}
+		agents = append(agents, agent)
+		bikes = append(bikes, seated.GetBike())
+	}
+	clear(s.GetDeadAgents())
+	seat := func() {
+		for i, agent := range agents {
+			agent.SetBike(bikes[i])
+			s.AddAgentToBike(agent)
+		}
+	}
+	for _, agent := range agents {
+		s.AddAgent(agent)
+	}
+	seat()
+	s.SetObservability(utils.PartialObservability)
+	s.UpdateGameStates()
+
+	// the views the riders get when they are built in ID order at the start of a game
+	s.ResetGameState()
+	seat()
+	gs := s.NewGameStateDump(0)
+	expected := make(map[uuid.UUID]map[uuid.UUID]float64)
+	for _, id := range sortedIDs(s.GetAgentMap()) {
+		expected[id] = make(map[uuid.UUID]float64)
+		for seenID, seen := range s.NewGameStateView(gs, id).GetAgents() {
+			expected[id][seenID] = seen.GetEnergyLevel()
+		}
+	}
+
+	s.ResetGameState()
+	seat()
+	s.GetLeavingDecisions(s.NewGameStateDump(0))
+
+	for _, agent := range agents {
+		assert.Equal(t, expected[agent.GetID()], agent.seen)
+	}
+}
+
+// returns the IDs of the agents in ID order
+func sortedIDs(agents map[uuid.UUID]objects.IBaseBiker) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(agents))
+	for id := range agents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
@@ -0,0 +1,149 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// kicks out a given rider and accepts nobody
+type ExileProtocol struct {
+	server.DictatorshipProtocol
+	exiled uuid.UUID
+}
+
+func (p ExileProtocol) Kickout(*server.Server, objects.IMegaBike) []uuid.UUID {
+	return []uuid.UUID{p.exiled}
+}
+
+func (p ExileProtocol) Joining(*server.Server, objects.IMegaBike, []uuid.UUID) []uuid.UUID {
+	return make([]uuid.UUID, 0)
+}
+
+// holds an election every round and counts them
+type SnapElectionProtocol struct {
+	server.DictatorshipProtocol
+	elections *int
+}
+
+func (p SnapElectionProtocol) ElectionDue(*server.Server, objects.IMegaBike) bool {
+	return true
+}
+
+func (p SnapElectionProtocol) Election(*server.Server, objects.IMegaBike) {
+	*p.elections++
+}
+
+// returns the protocol a server registered for a governance
+func protocolOf(t *testing.T, s server.IBaseBikerServer, governance utils.Governance) server.IGovernanceProtocol {
+	protocol, ok := s.GetGovernanceProtocol(governance)
+	assert.True(t, ok, "missing protocol for %s", governance)
+	return protocol
+}
+
+func TestEveryGovernanceHasAProtocol(t *testing.T) {
+	s := server.Initialize(0)
+	for governance := utils.Democracy; governance < utils.Invalid; governance++ {
+		_, ok := s.GetGovernanceProtocol(governance)
+		assert.True(t, ok, "missing protocol for %s", governance)
+	}
+}
+
+func TestDemocracyProtocolKickout(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.Democracy, 3)
+	agents[0].kick = []uuid.UUID{agents[2].GetID()}
+	agents[1].kick = []uuid.UUID{agents[2].GetID()}
+
+	kicked := protocolOf(t, s, utils.Democracy).Kickout(s.(*server.Server), bike)
+
+	assert.Equal(t, []uuid.UUID{agents[2].GetID()}, kicked)
+}
+
+func TestDictatorshipProtocolAllocation(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.Dictatorship, 3)
+	protocol := protocolOf(t, s, utils.Dictatorship)
+
+	protocol.Succession(s.(*server.Server), bike)
+	assert.Contains(t, []uuid.UUID{agents[0].GetID(), agents[1].GetID(), agents[2].GetID()}, bike.GetRuler())
+
+	dictator := s.GetAgentMap()[bike.GetRuler()]
+	assert.Equal(t, dictator.DecideDictatorAllocation(), protocol.Allocation(s.(*server.Server), bike))
+}
+
+func TestCouncilProtocolAllocation(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.Council, utils.CouncilSize+1)
+	for _, agent := range agents {
+		agent.favourites = []uuid.UUID{agents[0].GetID(), agents[1].GetID(), agents[2].GetID()}
+	}
+	protocol := protocolOf(t, s, utils.Council)
+
+	protocol.Succession(s.(*server.Server), bike)
+	allocation := protocol.Allocation(s.(*server.Server), bike)
+
+	assert.Len(t, bike.GetCouncil(), utils.CouncilSize)
+	total := 0.0
+	for _, share := range allocation {
+		total += share
+	}
+	assert.InDelta(t, 1.0, total, utils.Epsilon)
+	assert.IsType(t, voting.IdVoteMap{}, allocation)
+}
+
+func TestCustomProtocolIsUsedByTheServer(t *testing.T) {
+	const exile utils.Governance = utils.Invalid + 1
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, exile, 3)
+	s.RegisterGovernanceProtocol(exile, ExileProtocol{exiled: agents[1].GetID()})
+
+	kicked := s.HandleKickoutProcess()
+
+	assert.Equal(t, []uuid.UUID{agents[1].GetID()}, kicked)
+	assert.Len(t, bike.GetAgents(), 2)
+}
+
+func TestGovernanceWithoutAProtocolIsDemocratic(t *testing.T) {
+	const unknown utils.Governance = utils.Invalid + 2
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, unknown, 3)
+	agents[0].kick = []uuid.UUID{agents[2].GetID()}
+	agents[1].kick = []uuid.UUID{agents[2].GetID()}
+
+	kicked := s.HandleKickoutProcess()
+
+	assert.Equal(t, []uuid.UUID{agents[2].GetID()}, kicked)
+	assert.Equal(t, uuid.Nil, bike.GetRuler())
+}
+
+func TestProtocolsAreRegisteredOnTheirServer(t *testing.T) {
+	const exile utils.Governance = utils.Invalid + 1
+	s := server.Initialize(0)
+	other := server.Initialize(0)
+
+	s.RegisterGovernanceProtocol(exile, ExileProtocol{})
+
+	_, ok := s.GetGovernanceProtocol(exile)
+	assert.True(t, ok)
+	_, ok = other.GetGovernanceProtocol(exile)
+	assert.False(t, ok)
+}
+
+func TestProtocolDecidesWhenElectionsAreHeld(t *testing.T) {
+	const snap utils.Governance = utils.Invalid + 1
+	s := server.Initialize(0)
+	setupGovernance(s, snap, 3)
+	elections := 0
+	s.RegisterGovernanceProtocol(snap, SnapElectionProtocol{elections: &elections})
+
+	s.RunScheduledElections()
+	s.RunScheduledElections()
+
+	assert.Equal(t, 2, elections)
+}
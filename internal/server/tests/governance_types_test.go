@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/server"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type PartisanAgent struct {
+	*ShoppingAgent
+	favourites []uuid.UUID // the riders the agent votes for in elections
+	kick       []uuid.UUID // the riders the agent wants to kick out
+}
+
+func (a *PartisanAgent) VoteLeader() voting.IdVoteMap {
+	votes := make(voting.IdVoteMap)
+	for _, favourite := range a.favourites {
+		votes[favourite] = 1.0 / float64(len(a.favourites))
+	}
+	return votes
+}
+
+func (a *PartisanAgent) VoteForKickout() map[uuid.UUID]int {
+	votes := make(map[uuid.UUID]int)
+	for _, id := range a.kick {
+		votes[id] = 1
+	}
+	return votes
+}
+
+// seats riders on a bike with the given governance. The riders are ordered by ID
+func setupGovernance(s server.IBaseBikerServer, governance utils.Governance, riders int) (objects.IMegaBike, []*PartisanAgent) {
+	bike := seatShoppers(s)
+	agents := make([]*PartisanAgent, riders)
+	for i := range agents {
+		agents[i] = &PartisanAgent{ShoppingAgent: NewShoppingAgent(0)}
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].GetID().String() < agents[j].GetID().String()
+	})
+	for _, agent := range agents {
+		s.AddAgent(agent)
+		agent.SetBike(bike.GetID())
+		s.AddAgentToBike(agent)
+	}
+	bike.SetGovernance(governance)
+	s.UpdateGameStates()
+	return bike, agents
+}
+
+func TestRotatingLeadershipPassesTheLeadOn(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.RotatingLeadership, 4)
+
+	s.RunScheduledElections()
+	assert.Equal(t, agents[0].GetID(), bike.GetRuler())
+
+	// when the leader is kicked out the next rider takes over
+	for _, agent := range agents[1:] {
+		agent.kick = []uuid.UUID{agents[0].GetID()}
+	}
+	s.HandleKickoutProcess()
+	assert.Equal(t, agents[1].GetID(), bike.GetRuler())
+}
+
+func TestSortitionDrawsARider(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.Sortition, 4)
+
+	s.RunScheduledElections()
+
+	ids := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		ids = append(ids, agent.GetID())
+	}
+	assert.Contains(t, ids, bike.GetRuler())
+	term, ok := s.GetRulerTerm(bike.GetID())
+	assert.True(t, ok)
+	assert.Equal(t, bike.GetRuler(), term.Ruler)
+}
+
+func TestCouncilDecidesKickouts(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupGovernance(s, utils.Council, utils.CouncilSize+2)
+	council := []uuid.UUID{agents[1].GetID(), agents[2].GetID(), agents[3].GetID()}
+	outsider, member := agents[4], agents[1]
+	for _, agent := range agents {
+		agent.favourites = council
+		if agent == outsider {
+			agent.kick = []uuid.UUID{member.GetID()}
+		} else if agent != agents[0] {
+			agent.kick = []uuid.UUID{outsider.GetID()}
+		}
+	}
+
+	s.RunScheduledElections()
+	assert.ElementsMatch(t, council, bike.GetCouncil())
+	assert.Equal(t, uuid.Nil, bike.GetRuler())
+
+	// only the votes of the council count
+	kicked := s.HandleKickoutProcess()
+	assert.Equal(t, []uuid.UUID{outsider.GetID()}, kicked)
+}
@@ -0,0 +1,387 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/MattSScott/basePlatformSOMAS/messaging"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ChattyAgent struct {
+	*objects.BaseBiker
+	messages   int // the number of messages it sends every session
+	recipients []objects.IBaseBiker
+	received   int
+	phases     []utils.MessagingPhase // the phases it was asked for its messages in
+	claims     []utils.Forces         // forces it claims to have applied, one message each
+	receipts   []objects.DeliveryReceiptMessage
+}
+
+func (a *ChattyAgent) GetAllMessages([]objects.IBaseBiker) []messaging.IMessage[objects.IBaseBiker] {
+	a.phases = append(a.phases, a.GetMessagingPhase())
+	messages := make([]messaging.IMessage[objects.IBaseBiker], 0, a.messages)
+	for i := 0; i < a.messages; i++ {
+		messages = append(messages, objects.LootboxMessage{
+			BaseMessage: messaging.CreateMessage[objects.IBaseBiker](a, a.recipients),
+		})
+	}
+	for _, forces := range a.claims {
+		messages = append(messages, objects.ForcesMessage{
+			BaseMessage: messaging.CreateMessage[objects.IBaseBiker](a, a.recipients),
+			AgentId:     a.GetID(),
+			AgentForces: forces,
+		})
+	}
+	return messages
+}
+
+func (a *ChattyAgent) HandleLootboxMessage(msg objects.LootboxMessage) {
+	a.received++
+}
+
+func (a *ChattyAgent) HandleDeliveryReceiptMessage(msg objects.DeliveryReceiptMessage) {
+	a.receipts = append(a.receipts, msg)
+}
+
+// promises the forces it applies later in the round, and keeps its word
+type PromisingAgent struct {
+	*ChattyAgent
+	promise utils.Forces
+}
+
+func (a *PromisingAgent) DecideForce(uuid.UUID) {
+	a.SetForces(a.promise)
+}
+
+// the messages in the inbox of the agent sent by the given agent, leaving out those of the team agents
+func inboxFrom(agent *ChattyAgent, sender uuid.UUID) []objects.InboxMessage {
+	inbox := make([]objects.InboxMessage, 0)
+	for _, msg := range agent.GetInbox() {
+		if msg.Sender == sender {
+			inbox = append(inbox, msg)
+		}
+	}
+	return inbox
+}
+
+// seats the agents on the given bikes, placed at the given positions
+func setupMessaging(s server.IBaseBikerServer, positions []utils.Coordinates, seats []int) []*ChattyAgent {
+	bikes := make([]objects.IMegaBike, 0)
+	for _, bike := range s.GetMegaBikes() {
+		bikes = append(bikes, bike)
+	}
+	for i, position := range positions {
+		bikes[i].SetPhysicalState(utils.PhysicalState{Position: position, Mass: utils.MassBike})
+	}
+	agents := make([]*ChattyAgent, len(seats))
+	for i, seat := range seats {
+		agents[i] = &ChattyAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}
+		s.AddAgent(agents[i])
+		agents[i].SetBike(bikes[seat].GetID())
+		s.AddAgentToBike(agents[i])
+	}
+	s.UpdateGameStates()
+	return agents
+}
+
+func TestMessagingWithoutLimits(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+	agents[0].messages = 5
+	agents[0].recipients = []objects.IBaseBiker{agents[1]}
+	energy := agents[0].GetEnergyLevel()
+
+	s.RunMessagingSession()
+
+	assert.Equal(t, 5, agents[1].received)
+	assert.Equal(t, energy, agents[0].GetEnergyLevel())
+	assert.Zero(t, s.GetDroppedMessages(agents[0].GetID()))
+}
+
+func TestMessageQuotaAndCost(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingLimits(utils.MessagingLimits{Quota: 2, MessageCost: 0.01, RecipientCost: 0.005})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0, 0})
+	agents[0].messages = 3
+	agents[0].recipients = []objects.IBaseBiker{agents[1], agents[2]}
+	energy := agents[0].GetEnergyLevel()
+
+	s.RunMessagingSession()
+
+	// the third message goes over the quota and reaches neither recipient
+	assert.Equal(t, 2, agents[1].received)
+	assert.Equal(t, 2, agents[2].received)
+	assert.InDelta(t, energy-2*(0.01+2*0.005), agents[0].GetEnergyLevel(), 1e-9)
+	assert.Equal(t, 2, s.GetDroppedMessages(agents[0].GetID()))
+	assert.Equal(t, 2, s.NewGameStateDump(0).Agents[agents[0].GetID()].MessagesDropped)
+
+	// an agent that can't afford a message can't send it
+	agents[1].messages = 1
+	agents[1].recipients = []objects.IBaseBiker{agents[2]}
+	agents[1].UpdateEnergyLevel(0.01 - agents[1].GetEnergyLevel())
+	s.RunMessagingSession()
+	assert.Equal(t, 2, agents[2].received)
+	assert.Equal(t, 1, s.GetDroppedMessages(agents[1].GetID()))
+}
+
+func TestMessageRecipientsAndRange(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingLimits(utils.MessagingLimits{MaxRecipients: 2, Range: 10})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 20, Y: 0}}, []int{0, 1, 2, 0})
+	agents[0].messages = 1
+	agents[0].recipients = []objects.IBaseBiker{agents[1], agents[2]}
+
+	s.RunMessagingSession()
+
+	// the agent on the far bike is out of range
+	assert.Equal(t, 1, agents[1].received)
+	assert.Zero(t, agents[2].received)
+	assert.Equal(t, 1, s.GetDroppedMessages(agents[0].GetID()))
+	assert.Len(t, agents[0].receipts, 1)
+	assert.Equal(t, agents[2].GetID(), agents[0].receipts[0].Recipient)
+	assert.Equal(t, objects.RecipientOutOfRange, agents[0].receipts[0].Failure)
+
+	// a message with too many recipients isn't sent at all
+	agents[0].recipients = []objects.IBaseBiker{agents[1], agents[2], agents[3]}
+	s.RunMessagingSession()
+	assert.Equal(t, 1, agents[1].received)
+	assert.Zero(t, agents[3].received)
+	assert.Equal(t, 4, s.GetDroppedMessages(agents[0].GetID()))
+}
+
+func TestMessagingIsAtTheEndOfTheRoundByDefault(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+
+	for _, phase := range []utils.MessagingPhase{utils.BeforeLeaving, utils.BeforeDirectionVote, utils.BeforeAllocation} {
+		s.RunMessagingPhase(phase)
+	}
+	s.RunMessagingSession()
+
+	assert.Equal(t, []utils.MessagingPhase{utils.EndOfRound}, agents[0].phases)
+}
+
+func TestMessagingPhases(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingPhases(utils.BeforeDirectionVote, utils.EndOfRound)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+	agents[0].messages = 1
+	agents[0].recipients = []objects.IBaseBiker{agents[1]}
+
+	// the server doesn't run the phase
+	s.RunMessagingPhase(utils.BeforeLeaving)
+	assert.Empty(t, agents[0].phases)
+
+	s.RunMessagingPhase(utils.BeforeDirectionVote)
+	assert.Equal(t, []utils.MessagingPhase{utils.BeforeDirectionVote}, agents[0].phases)
+	assert.Equal(t, 1, agents[1].received)
+	inbox := inboxFrom(agents[1], agents[0].GetID())
+	assert.Len(t, inbox, 1)
+	assert.Equal(t, agents[0].GetID(), inbox[0].Sender)
+	assert.Equal(t, utils.BeforeDirectionVote, inbox[0].SentPhase)
+	assert.Equal(t, utils.BeforeDirectionVote, inbox[0].Phase)
+}
+
+func TestMessageDelay(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingPhases(utils.BeforeLeaving, utils.BeforeAllocation, utils.EndOfRound)
+	s.SetMessageChannel(utils.MessageChannel{Delay: 2})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+	agents[0].messages = 1
+	agents[0].recipients = []objects.IBaseBiker{agents[1]}
+
+	s.RunMessagingPhase(utils.BeforeLeaving)
+	s.RunMessagingPhase(utils.BeforeAllocation)
+	assert.Zero(t, agents[1].received)
+
+	// the message sent before leaving arrives two phases later
+	s.RunMessagingPhase(utils.EndOfRound)
+	assert.Equal(t, 1, agents[1].received)
+	inbox := inboxFrom(agents[1], agents[0].GetID())
+	assert.Len(t, inbox, 1)
+	assert.Equal(t, utils.BeforeLeaving, inbox[0].SentPhase)
+	assert.Equal(t, utils.EndOfRound, inbox[0].Phase)
+}
+
+func TestMessageDrops(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessageChannel(utils.MessageChannel{DropRate: 1.0})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0, 0})
+	agents[0].messages = 2
+	agents[0].recipients = []objects.IBaseBiker{agents[1], agents[2]}
+
+	s.RunMessagingSession()
+
+	assert.Zero(t, agents[1].received)
+	assert.Zero(t, agents[2].received)
+	assert.Equal(t, 4, s.GetDroppedMessages(agents[0].GetID()))
+}
+
+func TestMessageDropsAreReproducible(t *testing.T) {
+	s := server.Initialize(0)
+	// only the agents of the test send messages
+	for _, agent := range s.GetAgentMap() {
+		s.RemoveAgent(agent)
+	}
+	s.SetMessageChannel(utils.MessageChannel{DropRate: 0.5})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0, 0})
+	senders := []*ChattyAgent{agents[0], agents[1]}
+	for _, sender := range senders {
+		sender.messages = 10
+		sender.recipients = []objects.IBaseBiker{agents[2]}
+	}
+
+	s.RunMessagingSession()
+
+	// the drops are drawn from the seeded generator, one sender after the other in ID order
+	sort.Slice(senders, func(i, j int) bool {
+		return senders[i].GetID().String() < senders[j].GetID().String()
+	})
+	draws := rand.New(rand.NewSource(utils.MessagingSeed))
+	for _, sender := range senders {
+		dropped := 0
+		for i := 0; i < sender.messages; i++ {
+			if draws.Float64() < 0.5 {
+				dropped++
+			}
+		}
+		assert.Equal(t, dropped, s.GetDroppedMessages(sender.GetID()))
+	}
+}
+
+func TestMessageLog(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+	s.RunActionProcess()
+	forces := agents[0].GetForces()
+	lie := forces
+	lie.Pedal += 0.5
+	agents[0].messages = 1
+	agents[0].claims = []utils.Forces{forces, lie}
+	agents[0].recipients = []objects.IBaseBiker{agents[1]}
+
+	s.RunMessagingSession()
+
+	records := make([]server.MessageRecord, 0)
+	for _, record := range s.GetMessageLog() {
+		if record.Sender == agents[0].GetID() {
+			records = append(records, record)
+		}
+	}
+	assert.Len(t, records, 3)
+	assert.Equal(t, "LootboxMessage", records[0].Type)
+	assert.Equal(t, agents[1].GetID(), records[0].Recipient)
+	assert.Equal(t, utils.EndOfRound, records[0].Phase)
+	assert.Contains(t, records[0].Payload, "LootboxId")
+	// only the claims the server can check get a verdict
+	assert.Equal(t, objects.ClaimUnverifiable, records[0].Honesty)
+	assert.Equal(t, "ForcesMessage", records[1].Type)
+	assert.Equal(t, objects.ClaimTrue, records[1].Honesty)
+	assert.Equal(t, objects.ClaimFalse, records[2].Honesty)
+	assert.Len(t, s.NewGameStateDump(0).Messages, len(s.GetMessageLog()))
+}
+
+func TestNetworkStatistics(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	gameState := server.GameStateDump{
+		Agents: map[uuid.UUID]server.AgentDump{
+			a: {ID: a, Class: "team1.Biker1"},
+			b: {ID: b, Class: "team1.Biker1"},
+			c: {ID: c, Class: "team2.Biker2"},
+		},
+		Messages: []server.MessageRecord{
+			{Sender: a, Recipient: b, Honesty: objects.ClaimTrue},
+			{Sender: b, Recipient: a, Honesty: objects.ClaimFalse},
+			{Sender: a, Recipient: c, Honesty: objects.ClaimUnverifiable},
+			{Sender: a, Recipient: c, Honesty: objects.ClaimUnverifiable},
+			{Sender: c, Recipient: b, Honesty: objects.ClaimUnverifiable},
+		},
+	}
+
+	statistics := server.CalculateStatistics([][]server.GameStateDump{{gameState}})
+
+	network := statistics.Network[0]
+	assert.Equal(t, 5, network.Messages)
+	assert.Equal(t, 4, network.Links)
+	assert.Equal(t, 2.0, network.AverageDegree)
+	assert.Equal(t, 0.5, network.Reciprocity)
+	assert.Equal(t, 1.0, network.Clustering)
+	assert.Equal(t, 0.4, network.InTeamShare)
+	assert.Equal(t, 0.5, network.HonestyRate)
+	assert.Equal(t, 2.0, statistics.PerRound[0].AgentDegree[a])
+}
+
+func TestUndeliverableMessages(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0, 0})
+	s.RemoveAgent(agents[1])
+	stranger := &ChattyAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}
+	agents[0].messages = 1
+	agents[0].recipients = []objects.IBaseBiker{agents[1], stranger, nil, agents[2]}
+
+	assert.NotPanics(t, s.RunMessagingSession)
+
+	// the dead, the unknown and the missing recipients are dropped, the others still get the message
+	assert.Zero(t, agents[1].received)
+	assert.Equal(t, 1, agents[2].received)
+	assert.Equal(t, 3, s.GetDroppedMessages(agents[0].GetID()))
+	assert.Len(t, agents[0].receipts, 3)
+	for _, receipt := range agents[0].receipts {
+		assert.Equal(t, objects.UnknownRecipient, receipt.Failure)
+		assert.IsType(t, objects.LootboxMessage{}, receipt.Message)
+	}
+	assert.Equal(t, stranger.GetID(), agents[0].receipts[1].Recipient)
+}
+
+func TestRecipientDiesBeforeDelivery(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessageChannel(utils.MessageChannel{Delay: 1})
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0, 0})
+	agents[0].messages = 1
+	agents[0].recipients = []objects.IBaseBiker{agents[1]}
+
+	s.RunMessagingSession()
+	agents[0].messages = 0
+	s.RemoveAgent(agents[1])
+	s.RunMessagingSession()
+
+	assert.Zero(t, agents[1].received)
+	assert.Len(t, agents[0].receipts, 1)
+	assert.Equal(t, objects.RecipientDied, agents[0].receipts[0].Failure)
+}
+
+func TestClaimsAreCheckedOnceTheRoundIsOver(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetMessagingPhases(utils.BeforeDirectionVote, utils.EndOfRound)
+	agents := setupMessaging(s, []utils.Coordinates{{X: 0, Y: 0}}, []int{0})
+	promiser := &PromisingAgent{ChattyAgent: &ChattyAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}}
+	s.AddAgent(promiser)
+	promiser.SetBike(agents[0].GetBike())
+	s.AddAgentToBike(promiser)
+	s.UpdateGameStates()
+	promiser.promise = utils.Forces{Pedal: 0.5}
+	promiser.claims = []utils.Forces{promiser.promise}
+	promiser.recipients = []objects.IBaseBiker{agents[0]}
+
+	// the forces are promised before they are applied
+	s.RunMessagingPhase(utils.BeforeDirectionVote)
+	promiser.claims = nil
+	s.RunActionProcess()
+	s.RunMessagingSession()
+
+	records := make([]server.MessageRecord, 0)
+	for _, record := range s.GetMessageLog() {
+		if record.Sender == promiser.GetID() {
+			records = append(records, record)
+		}
+	}
+	assert.Len(t, records, 1)
+	assert.Equal(t, utils.BeforeDirectionVote, records[0].SentPhase)
+	assert.Equal(t, objects.ClaimTrue, records[0].Honesty)
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type YieldingAgent struct {
+	*objects.BaseBiker
+}
+
+func NewYieldingAgent() *YieldingAgent {
+	return &YieldingAgent{
+		BaseBiker: objects.GetBaseBiker(utils.GenerateRandomColour(), uuid.New()),
+	}
+}
+
+// always gives up the contested lootboxes
+func (a *YieldingAgent) DecideNegotiationOffers(contested map[uuid.UUID][]uuid.UUID) []objects.NegotiationOfferMessage {
+	offers := make([]objects.NegotiationOfferMessage, 0)
+	for lootboxId, bikes := range contested {
+		for _, bikeId := range bikes {
+			if bikeId != a.GetBike() {
+				offers = append(offers, a.CreateNegotiationOfferMessage(lootboxId, bikeId, objects.Yield, 0.0, 0.0))
+			}
+		}
+	}
+	return offers
+}
+
+// doesn't entertain offers from other bikes
+func (a *YieldingAgent) RespondToNegotiationOffer(objects.NegotiationOfferMessage) bool {
+	return false
+}
+
+// places the two occupied bikes on top of the same lootbox, so that they are both aiming for it
+func setupContestedLootbox(t *testing.T) (server.IBaseBikerServer, objects.ILootBox, []objects.IMegaBike) {
+	OnlySpawnBaseBikers(t)
+	s := server.Initialize(1)
+	s.UpdateGameStates()
+	s.FoundingInstitutions()
+
+	var lootbox objects.ILootBox
+	for _, lootbox = range s.GetLootBoxes() {
+		break
+	}
+	// move the other lootboxes out of the way so that only one is collected
+	for id, other := range s.GetLootBoxes() {
+		if id != lootbox.GetID() {
+			state := other.GetPhysicalState()
+			state.Position = utils.Coordinates{X: -1000.0, Y: -1000.0}
+			other.SetPhysicalState(state)
+		}
+	}
+	bikes := make([]objects.IMegaBike, 0)
+	for _, bike := range s.GetMegaBikes() {
+		if len(bike.GetAgents()) == 0 {
+			continue
+		}
+		state := bike.GetPhysicalState()
+		state.Position = lootbox.GetPosition()
+		bike.SetPhysicalState(state)
+		bikes = append(bikes, bike)
+	}
+	s.UpdateGameStates()
+	return s, lootbox, bikes
+}
+
+func TestNegotiationSplitsContestedLootbox(t *testing.T) {
+	s, lootbox, bikes := setupContestedLootbox(t)
+
+	contested := s.GetContestedLootBoxes()
+	assert.ElementsMatch(t, []uuid.UUID{bikes[0].GetID(), bikes[1].GetID()}, contested[lootbox.GetID()])
+
+	s.RunNegotiationSession()
+	agreements := s.GetNegotiationAgreements()[lootbox.GetID()]
+	assert.Len(t, agreements, 1, "base bikers should agree on exactly one split")
+	assert.Equal(t, objects.SplitProposal, agreements[0].OfferType)
+	assert.Equal(t, 0.5, agreements[0].ProposerShare)
+}
+
+func TestNegotiatedYieldIsEnforced(t *testing.T) {
+	s, lootbox, bikes := setupContestedLootbox(t)
+
+	// make the yielding agent the dictator of the first bike
+	yieldingBike := bikes[0]
+	yielder := NewYieldingAgent()
+	s.AddAgent(yielder)
+	yielder.SetBike(yieldingBike.GetID())
+	s.AddAgentToBike(yielder)
+	yieldingBike.SetGovernance(utils.Dictatorship)
+	yieldingBike.SetRuler(yielder.GetID())
+	s.UpdateGameStates()
+
+	s.RunNegotiationSession()
+	assert.Len(t, s.GetNegotiationAgreements()[lootbox.GetID()], 1)
+
+	energies := make(map[uuid.UUID]float64)
+	for _, agent := range s.GetAgentMap() {
+		agent.UpdateEnergyLevel(-0.9)
+		energies[agent.GetID()] = agent.GetEnergyLevel()
+	}
+	s.LootboxCheckAndDistributions()
+
+	for _, agent := range yieldingBike.GetAgents() {
+		assert.Equal(t, energies[agent.GetID()], agent.GetEnergyLevel(), "riders of the yielding bike shouldn't get any loot")
+	}
+	for _, agent := range bikes[1].GetAgents() {
+		assert.Greater(t, agent.GetEnergyLevel(), energies[agent.GetID()], "riders of the other bike should get the loot")
+	}
+	_, exists := s.GetLootBoxes()[lootbox.GetID()]
+	assert.False(t, exists, "looted lootbox should despawn")
+	assert.Empty(t, s.GetNegotiationAgreements()[lootbox.GetID()], "agreement should be discarded once settled")
+}
+
+func TestNegotiationAgreementsExpire(t *testing.T) {
+	s, lootbox, bikes := setupContestedLootbox(t)
+	s.RunNegotiationSession()
+	assert.Equal(t, objects.SplitProposal, s.GetNegotiationAgreements()[lootbox.GetID()][0].OfferType)
+
+	// the next round the bikes negotiate again, on the new terms of the first bike
+	yielder := NewYieldingAgent()
+	s.AddAgent(yielder)
+	yielder.SetBike(bikes[0].GetID())
+	s.AddAgentToBike(yielder)
+	bikes[0].SetGovernance(utils.Dictatorship)
+	bikes[0].SetRuler(yielder.GetID())
+	s.UpdateGameStates()
+
+	s.RunNegotiationSession()
+	agreements := s.GetNegotiationAgreements()[lootbox.GetID()]
+	assert.Len(t, agreements, 1)
+	assert.Equal(t, objects.Yield, agreements[0].OfferType)
+}
+
+func TestNegotiationFollowsBikeOrder(t *testing.T) {
+	s, lootbox, bikes := setupContestedLootbox(t)
+	for _, bike := range bikes {
+		yielder := NewYieldingAgent()
+		s.AddAgent(yielder)
+		yielder.SetBike(bike.GetID())
+		s.AddAgentToBike(yielder)
+		bike.SetGovernance(utils.Dictatorship)
+		bike.SetRuler(yielder.GetID())
+	}
+	s.UpdateGameStates()
+
+	// both bikes yield, the first one to make an offer is the one with the lowest ID
+	s.RunNegotiationSession()
+	agreements := s.GetNegotiationAgreements()[lootbox.GetID()]
+	first := bikes[0].GetID()
+	if bikes[1].GetID().String() < first.String() {
+		first = bikes[1].GetID()
+	}
+	assert.Len(t, agreements, 1)
+	assert.Equal(t, first, agreements[0].ProposerBike)
+}
+
+type ProposalCountingAgent struct {
+	*objects.BaseBiker
+	proposals int
+}
+
+func (a *ProposalCountingAgent) ProposeDirection() uuid.UUID {
+	a.proposals++
+	return a.BaseBiker.ProposeDirection()
+}
+
+func TestNegotiatorProposesOncePerRound(t *testing.T) {
+	s, _, bikes := setupContestedLootbox(t)
+	negotiator := &ProposalCountingAgent{BaseBiker: objects.GetBaseBiker(utils.GenerateRandomColour(), uuid.New())}
+	s.AddAgent(negotiator)
+	negotiator.SetBike(bikes[0].GetID())
+	s.AddAgentToBike(negotiator)
+	bikes[0].SetGovernance(utils.Leadership)
+	bikes[0].SetRuler(negotiator.GetID())
+	s.UpdateGameStates()
+
+	// the negotiation and the direction vote share the proposal
+	s.RunNegotiationSession()
+	s.RunActionProcess()
+	assert.Equal(t, 1, negotiator.proposals)
+}
@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type NoticeAgent struct {
+	*objects.BaseBiker
+	posts []objects.NoticeBoardPost
+}
+
+func (a *NoticeAgent) DecideNoticeBoardPosts() []objects.NoticeBoardPost {
+	posts := a.posts
+	a.posts = nil
+	return posts
+}
+
+func setupNoticeBoard(s server.IBaseBikerServer, governance utils.Governance, riders int) (objects.IMegaBike, []*NoticeAgent) {
+	bike := seatShoppers(s)
+	agents := make([]*NoticeAgent, riders)
+	for i := range agents {
+		agents[i] = &NoticeAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}
+		s.AddAgent(agents[i])
+		agents[i].SetBike(bike.GetID())
+		s.AddAgentToBike(agents[i])
+	}
+	bike.SetGovernance(governance)
+	if governance != utils.Democracy {
+		bike.SetRuler(agents[0].GetID())
+	}
+	s.UpdateGameStates()
+	return bike, agents
+}
+
+func TestDemocraticNoticeBoard(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupNoticeBoard(s, utils.Democracy, 2)
+	agents[1].posts = []objects.NoticeBoardPost{{Action: objects.PostNotice, Kind: objects.PlanNotice, Text: "head north"}}
+	s.RunNoticeBoards()
+
+	notices := bike.GetNotices()
+	assert.Len(t, notices, 1)
+	assert.Equal(t, agents[1].GetID(), notices[0].Author)
+	assert.Equal(t, objects.PlanNotice, notices[0].Kind)
+
+	// only the author can change its notice
+	agents[0].posts = []objects.NoticeBoardPost{{Action: objects.RemoveNotice, NoticeID: notices[0].ID}}
+	agents[1].posts = []objects.NoticeBoardPost{{Action: objects.EditNotice, NoticeID: notices[0].ID, Kind: objects.PlanNotice, Text: "head south"}}
+	s.RunNoticeBoards()
+	assert.Len(t, bike.GetNotices(), 1)
+	assert.Equal(t, "head south", bike.GetNotices()[0].Text)
+	assert.Equal(t, agents[1].GetID(), bike.GetNotices()[0].Editor)
+
+	agents[1].posts = []objects.NoticeBoardPost{{Action: objects.RemoveNotice, NoticeID: notices[0].ID}}
+	s.RunNoticeBoards()
+	assert.Empty(t, bike.GetNotices())
+}
+
+func TestNoticeBoardGovernance(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupNoticeBoard(s, utils.Dictatorship, 2)
+	agents[1].posts = []objects.NoticeBoardPost{{Action: objects.PostNotice, Kind: objects.ProposalNotice, Text: "let me steer"}}
+	agents[0].posts = []objects.NoticeBoardPost{{Action: objects.PostNotice, Kind: objects.RuleNotice, Text: "everyone pedals"}}
+	s.RunNoticeBoards()
+	// only the dictator posts
+	assert.Len(t, bike.GetNotices(), 1)
+	assert.Equal(t, agents[0].GetID(), bike.GetNotices()[0].Author)
+
+	// under a leader the riders make proposals, but plans and rules are the leader's
+	bike.SetGovernance(utils.Leadership)
+	agents[1].posts = []objects.NoticeBoardPost{
+		{Action: objects.PostNotice, Kind: objects.RuleNotice, Text: "nobody brakes"},
+		{Action: objects.PostNotice, Kind: objects.ProposalNotice, Text: "let me steer"},
+	}
+	s.RunNoticeBoards()
+	notices := bike.GetNotices()
+	assert.Len(t, notices, 2)
+	assert.Equal(t, objects.ProposalNotice, notices[1].Kind)
+	// and the leader can change anyone's notice
+	agents[0].posts = []objects.NoticeBoardPost{{Action: objects.RemoveNotice, NoticeID: notices[1].ID}}
+	s.RunNoticeBoards()
+	assert.Len(t, bike.GetNotices(), 1)
+}
+
+func TestNoticeBoardLimits(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupNoticeBoard(s, utils.Democracy, 1)
+	agents[0].posts = []objects.NoticeBoardPost{{Action: objects.PostNotice, Text: strings.Repeat("a", utils.NoticeLength+1)}}
+	for i := 0; i <= utils.NoticeBoardSize; i++ {
+		agents[0].posts = append(agents[0].posts, objects.NoticeBoardPost{Action: objects.PostNotice, Text: "hello"})
+	}
+	s.RunNoticeBoards()
+	assert.Len(t, bike.GetNotices(), utils.NoticeBoardSize)
+}
+
+func TestNoticeBoardVisibility(t *testing.T) {
+	s := server.Initialize(0)
+	s.SetObservability(utils.PartialObservability)
+	agents := setupViews(s)
+	bike := s.GetMegaBikes()[agents[0].GetBike()]
+	bike.SetNotices([]objects.Notice{{ID: uuid.New(), Author: agents[0].GetID(), Text: "head north"}})
+	gs := s.NewGameStateDump(0)
+
+	// fellow riders read the notice board, the riders of other bikes don't
+	assert.Len(t, s.NewGameStateView(gs, agents[1].GetID()).GetMegaBikes()[bike.GetID()].GetNotices(), 1)
+	assert.Empty(t, s.NewGameStateView(gs, agents[2].GetID()).GetMegaBikes()[bike.GetID()].GetNotices())
+}
+
+func TestNoticeBoardIsPrivateUnderFullObservability(t *testing.T) {
+	s := server.Initialize(0)
+	agents := setupViews(s)
+	bike := s.GetMegaBikes()[agents[0].GetBike()]
+	bike.SetNotices([]objects.Notice{{ID: uuid.New(), Author: agents[0].GetID(), Text: "head north"}})
+
+	s.UpdateGameStates()
+
+	// every agent sees every bike, but only the riders read its notice board
+	assert.Len(t, agents[1].GetGameState().GetMegaBikes()[bike.GetID()].GetNotices(), 1)
+	assert.Empty(t, agents[2].GetGameState().GetMegaBikes()[bike.GetID()].GetNotices())
+	assert.Empty(t, agents[3].GetGameState().GetMegaBikes()[bike.GetID()].GetNotices())
+	assert.Len(t, agents[2].GetGameState().GetAgents(), len(s.GetAgentMap()))
+}
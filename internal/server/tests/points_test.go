@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ShoppingAgent struct {
+	*objects.BaseBiker
+	purchases []objects.PointsPurchase
+}
+
+func NewShoppingAgent(points int) *ShoppingAgent {
+	agent := &ShoppingAgent{
+		BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New()),
+		purchases: make([]objects.PointsPurchase, 0),
+	}
+	agent.SetColour(utils.Red)
+	agent.UpdatePoints(points)
+	return agent
+}
+
+func (a *ShoppingAgent) DecidePointsPurchases() []objects.PointsPurchase {
+	purchases := a.purchases
+	a.purchases = make([]objects.PointsPurchase, 0)
+	return purchases
+}
+
+// puts the agents on the same bike and returns the bike
+func seatShoppers(s server.IBaseBikerServer, agents ...*ShoppingAgent) objects.IMegaBike {
+	var bike objects.IMegaBike
+	for _, bike = range s.GetMegaBikes() {
+		break
+	}
+	for _, agent := range agents {
+		s.AddAgent(agent)
+		agent.SetBike(bike.GetID())
+		s.AddAgentToBike(agent)
+	}
+	return bike
+}
+
+func TestColourChangePurchase(t *testing.T) {
+	s := server.Initialize(1)
+	agent := NewShoppingAgent(utils.ColourChangeCost)
+	seatShoppers(s, agent)
+	agent.purchases = append(agent.purchases, objects.PointsPurchase{Type: objects.ColourChangePurchase, Colour: utils.Blue})
+
+	s.RunPointsPurchases()
+
+	assert.Equal(t, utils.Blue, agent.GetColour())
+	assert.Equal(t, 0, agent.GetPoints())
+	ledger := s.GetPointsLedger()
+	assert.Len(t, ledger, 1)
+	assert.Equal(t, objects.ColourChangePurchase, ledger[0].Type)
+	assert.Equal(t, uuid.Nil, ledger[0].To)
+}
+
+func TestBribeIsPaidToRuler(t *testing.T) {
+	s := server.Initialize(1)
+	briber, ruler := NewShoppingAgent(10), NewShoppingAgent(0)
+	bike := seatShoppers(s, briber, ruler)
+	bike.SetGovernance(utils.Dictatorship)
+	bike.SetRuler(ruler.GetID())
+	briber.purchases = append(briber.purchases, objects.PointsPurchase{Type: objects.BribePurchase, Points: 4})
+
+	s.RunPointsPurchases()
+
+	assert.Equal(t, 6, briber.GetPoints())
+	assert.Equal(t, 4, ruler.GetPoints())
+	ledger := s.GetPointsLedger()
+	assert.Len(t, ledger, 1)
+	assert.Equal(t, ruler.GetID(), ledger[0].To)
+}
+
+func TestInvalidPointsPurchasesAreIgnored(t *testing.T) {
+	s := server.Initialize(1)
+	agent := NewShoppingAgent(3)
+	seatShoppers(s, agent)
+	agent.purchases = append(agent.purchases,
+		objects.PointsPurchase{Type: objects.VoteWeightPurchase, Points: 4},                  // more points than the agent has
+		objects.PointsPurchase{Type: objects.BribePurchase, Points: 1},                       // the bike has no ruler
+		objects.PointsPurchase{Type: objects.ColourChangePurchase, Colour: utils.Blue},       // can't afford it
+		objects.PointsPurchase{Type: objects.BikeEntryPurchase},                              // already on a bike
+		objects.PointsPurchase{Type: objects.ColourChangePurchase, Colour: utils.Colour(-1)}, // not a colour
+	)
+
+	s.RunPointsPurchases()
+
+	assert.Equal(t, 3, agent.GetPoints())
+	assert.Equal(t, utils.Red, agent.GetColour())
+	assert.Empty(t, s.GetPointsLedger())
+}
+
+func TestPaidEntryToFullBike(t *testing.T) {
+	s := server.Initialize(0)
+	riders := make([]*ShoppingAgent, utils.BikersOnBike)
+	for i := range riders {
+		riders[i] = NewShoppingAgent(0)
+	}
+	bike := seatShoppers(s, riders...)
+	entrant := NewShoppingAgent(utils.BikeEntryCost)
+	s.AddAgent(entrant)
+	entrant.SetBike(bike.GetID())
+	entrant.ToggleOnBike()
+	entrant.purchases = append(entrant.purchases, objects.PointsPurchase{Type: objects.BikeEntryPurchase})
+
+	s.RunPointsPurchases()
+	s.ProcessJoiningRequests(make([]uuid.UUID, 0))
+
+	assert.True(t, entrant.GetBikeStatus())
+	assert.Len(t, bike.GetAgents(), utils.BikersOnBike+1)
+	assert.Equal(t, 0, entrant.GetPoints())
+	total := 0
+	for _, transaction := range s.GetPointsLedger() {
+		assert.Equal(t, entrant.GetID(), transaction.From)
+		total += transaction.Points
+	}
+	assert.Equal(t, utils.BikeEntryCost, total)
+}
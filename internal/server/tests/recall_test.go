@@ -0,0 +1,130 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/common/voting"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type RebelAgent struct {
+	*ShoppingAgent
+	rebel     bool
+	candidate uuid.UUID // the rider it votes for in elections, if any
+}
+
+func (a *RebelAgent) VoteLeader() voting.IdVoteMap {
+	if a.candidate == uuid.Nil {
+		return a.ShoppingAgent.VoteLeader()
+	}
+	return voting.IdVoteMap{a.candidate: 1.0}
+}
+
+func (a *RebelAgent) DecideRecall() bool {
+	return a.rebel
+}
+
+func (a *RebelAgent) VoteRecall() bool {
+	return a.rebel
+}
+
+// seats riders on a bike ruled by the first of them, the next rebels of them want the ruler gone
+func setupRecall(s server.IBaseBikerServer, governance utils.Governance, riders int, rebels int) (objects.IMegaBike, []*RebelAgent) {
+	bike := seatShoppers(s)
+	agents := make([]*RebelAgent, riders)
+	for i := range agents {
+		agents[i] = &RebelAgent{ShoppingAgent: NewShoppingAgent(0), rebel: i > 0 && i <= rebels}
+		s.AddAgent(agents[i])
+		agents[i].SetBike(bike.GetID())
+		s.AddAgentToBike(agents[i])
+	}
+	bike.SetGovernance(governance)
+	bike.SetRuler(agents[0].GetID())
+	s.UpdateGameStates()
+	return bike, agents
+}
+
+func TestRecallRemovesLeader(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Leadership, 4, 2)
+	// even if everyone still wants the ruler to lead
+	for _, agent := range agents {
+		agent.candidate = agents[0].GetID()
+	}
+
+	s.RunRecallVote(bike)
+
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.RulerRecalledEvent, events[0].Type)
+	assert.NotEqual(t, agents[0].GetID(), bike.GetRuler(), "the recalled ruler can't be elected again")
+	assert.Contains(t, []uuid.UUID{agents[1].GetID(), agents[2].GetID(), agents[3].GetID()}, bike.GetRuler())
+	assert.InDelta(t, 1.0-utils.RecallPetitionCost, agents[1].GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 1.0, agents[3].GetEnergyLevel(), utils.Epsilon)
+}
+
+func TestDictatorSurvivesWithoutSupermajority(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Dictatorship, 4, 2)
+
+	s.RunRecallVote(bike)
+
+	assert.Equal(t, agents[0].GetID(), bike.GetRuler())
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.RecallVoteEvent, events[0].Type)
+}
+
+func TestRecallNeedsQuorum(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Leadership, 8, 1)
+
+	s.RunRecallVote(bike)
+
+	assert.Equal(t, agents[0].GetID(), bike.GetRuler())
+	assert.Empty(t, s.GetEvents())
+	// the petitioner pays even though no vote is held
+	assert.InDelta(t, 1.0-utils.RecallPetitionCost, agents[1].GetEnergyLevel(), utils.Epsilon)
+}
+
+func TestRecallRemovesSortitionRuler(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Sortition, 4, 2)
+
+	s.RunRecallVote(bike)
+
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.RulerRecalledEvent, events[0].Type)
+	assert.Contains(t, []uuid.UUID{agents[1].GetID(), agents[2].GetID(), agents[3].GetID()}, bike.GetRuler())
+}
+
+func TestRecallRemovesCouncil(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Council, 6, 3)
+	bike.SetRuler(uuid.Nil)
+	council := []uuid.UUID{agents[0].GetID(), agents[4].GetID(), agents[5].GetID()}
+	bike.SetCouncil(council)
+
+	s.RunRecallVote(bike)
+
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.RulerRecalledEvent, events[0].Type)
+	assert.ElementsMatch(t, []uuid.UUID{agents[1].GetID(), agents[2].GetID(), agents[3].GetID()}, bike.GetCouncil())
+	assert.Equal(t, uuid.Nil, bike.GetRuler())
+}
+
+func TestDemocracyHasNobodyToRecall(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRecall(s, utils.Democracy, 4, 3)
+
+	s.RunRecallVote(bike)
+
+	assert.Empty(t, s.GetEvents())
+	assert.InDelta(t, 1.0, agents[1].GetEnergyLevel(), utils.Epsilon)
+}
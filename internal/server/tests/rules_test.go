@@ -0,0 +1,188 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/objects"
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type RuleAgent struct {
+	*objects.BaseBiker
+	proposals []objects.RuleAmendment
+	support   bool
+	pedal     float64
+}
+
+func (a *RuleAgent) ProposeRuleAmendments() []objects.RuleAmendment {
+	proposals := a.proposals
+	a.proposals = nil
+	return proposals
+}
+
+func (a *RuleAgent) VoteRuleAmendment(amendment objects.RuleAmendment) bool {
+	return a.support
+}
+
+func (a *RuleAgent) DecideForce(direction uuid.UUID) {
+	a.SetForces(utils.Forces{Pedal: a.pedal})
+}
+
+func setupRules(s server.IBaseBikerServer, governance utils.Governance, riders int) (objects.IMegaBike, []*RuleAgent) {
+	bike := seatShoppers(s)
+	agents := make([]*RuleAgent, riders)
+	for i := range agents {
+		agents[i] = &RuleAgent{BaseBiker: objects.GetBaseBiker(utils.Red, uuid.New())}
+		s.AddAgent(agents[i])
+		agents[i].SetBike(bike.GetID())
+		s.AddAgentToBike(agents[i])
+	}
+	bike.SetGovernance(governance)
+	if governance != utils.Democracy {
+		bike.SetRuler(agents[0].GetID())
+	}
+	s.UpdateGameStates()
+	return bike, agents
+}
+
+func TestDemocraticRuleAmendments(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Democracy, 3)
+	minimumPedal := objects.Rule{Kind: objects.MinimumPedalRule, Value: 0.5, Fine: 0.1}
+
+	// a minority isn't enough to enact a rule
+	agents[0].support = true
+	agents[0].proposals = []objects.RuleAmendment{{Action: objects.EnactRule, Rule: minimumPedal}}
+	s.RunRuleAmendments()
+	assert.Empty(t, bike.GetRules())
+	assert.Equal(t, 1, countEvents(s, server.RuleVoteEvent))
+	assert.InDelta(t, 1.0-utils.RuleAmendmentCost, agents[0].GetEnergyLevel(), utils.Epsilon)
+
+	agents[1].support = true
+	agents[0].proposals = []objects.RuleAmendment{{Action: objects.EnactRule, Rule: minimumPedal}}
+	s.RunRuleAmendments()
+	rules := bike.GetRules()
+	assert.Len(t, rules, 1)
+	assert.Equal(t, objects.MinimumPedalRule, rules[0].Kind)
+	assert.NotEqual(t, uuid.Nil, rules[0].ID)
+
+	// amending keeps the rule, repealing removes it
+	amended := rules[0]
+	amended.Value = 0.8
+	agents[2].proposals = []objects.RuleAmendment{{Action: objects.AmendRule, Rule: amended}}
+	s.RunRuleAmendments()
+	assert.Len(t, bike.GetRules(), 1)
+	assert.Equal(t, rules[0].ID, bike.GetRules()[0].ID)
+	assert.InDelta(t, 0.8, bike.GetRules()[0].Value, utils.Epsilon)
+
+	agents[2].proposals = []objects.RuleAmendment{{Action: objects.RepealRule, Rule: amended}}
+	s.RunRuleAmendments()
+	assert.Empty(t, bike.GetRules())
+	assert.Equal(t, 3, countEvents(s, server.RuleChangeEvent))
+
+	// a rule that doesn't exist can't be repealed
+	agents[2].proposals = []objects.RuleAmendment{{Action: objects.RepealRule, Rule: amended}}
+	s.RunRuleAmendments()
+	assert.Equal(t, 3, countEvents(s, server.RuleChangeEvent))
+}
+
+func TestDictatorDecidesRules(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Dictatorship, 3)
+	agents[1].support = true
+	agents[2].support = true
+	agents[1].proposals = []objects.RuleAmendment{{Action: objects.EnactRule, Rule: objects.Rule{Kind: objects.EffortShareRule}}}
+	s.RunRuleAmendments()
+	assert.Empty(t, bike.GetRules())
+
+	agents[0].support = true
+	agents[1].proposals = []objects.RuleAmendment{{Action: objects.EnactRule, Rule: objects.Rule{Kind: objects.EffortShareRule}}}
+	s.RunRuleAmendments()
+	assert.Len(t, bike.GetRules(), 1)
+}
+
+func TestRuleValuesAreSanitised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Dictatorship, 1)
+	agents[0].support = true
+	agents[0].proposals = []objects.RuleAmendment{
+		{Action: objects.EnactRule, Rule: objects.Rule{Kind: objects.MinimumPedalRule, Value: 2.0, Fine: -1.0}},
+		{Action: objects.EnactRule, Rule: objects.Rule{Kind: objects.ViolationKickoutRule, Value: 0.0}},
+	}
+	s.RunRuleAmendments()
+	rules := bike.GetRules()
+	assert.Len(t, rules, 2)
+	assert.InDelta(t, 1.0, rules[0].Value, utils.Epsilon)
+	assert.InDelta(t, 0.0, rules[0].Fine, utils.Epsilon)
+	assert.InDelta(t, 1.0, rules[1].Value, utils.Epsilon)
+}
+
+func TestUnknownRuleAmendmentsArePenalised(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Democracy, 2)
+	for _, agent := range agents {
+		agent.support = true
+	}
+	agents[0].proposals = []objects.RuleAmendment{
+		{Action: objects.EnactRule, Rule: objects.Rule{Kind: objects.RuleKind(42)}},
+		{Action: objects.AmendmentAction(-1), Rule: objects.Rule{Kind: objects.MinimumPedalRule}},
+	}
+	s.RunRuleAmendments()
+
+	// neither proposal is put to the vote, and the proposer pays the penalty for both
+	assert.Empty(t, bike.GetRules())
+	assert.Equal(t, 0, countEvents(s, server.RuleVoteEvent))
+	assert.Equal(t, 2, countEvents(s, server.InvalidBallotEvent))
+	assert.InDelta(t, 1.0-2*(utils.RuleAmendmentCost+utils.InvalidBallotPenalty), agents[0].GetEnergyLevel(), utils.Epsilon)
+}
+
+func TestRuleEnforcement(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Democracy, 2)
+	agents[0].pedal = 1.0
+	bike.SetRules([]objects.Rule{
+		{ID: uuid.New(), Kind: objects.MinimumPedalRule, Value: 0.5, Fine: 0.1},
+		{ID: uuid.New(), Kind: objects.ViolationKickoutRule, Value: 2},
+	})
+	for _, agent := range agents {
+		agent.DecideForce(uuid.Nil)
+	}
+
+	// the rider that didn't pedal is fined
+	s.EnforceRules()
+	assert.InDelta(t, 1.0, agents[0].GetEnergyLevel(), utils.Epsilon)
+	assert.InDelta(t, 0.9, agents[1].GetEnergyLevel(), utils.Epsilon)
+	assert.Equal(t, 1, countEvents(s, server.RuleSanctionEvent))
+	assert.Len(t, bike.GetAgents(), 2)
+
+	// and kicked out the second time
+	s.EnforceRules()
+	assert.Equal(t, 3, countEvents(s, server.RuleSanctionEvent))
+	assert.Len(t, bike.GetAgents(), 1)
+	assert.Equal(t, agents[0].GetID(), bike.GetAgents()[0].GetID())
+}
+
+func TestEffortShareRule(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Democracy, 2)
+	agents[0].pedal = 1.0
+	bike.SetRules([]objects.Rule{{ID: uuid.New(), Kind: objects.EffortShareRule}})
+	s.RunActionProcess()
+	before := []float64{agents[0].GetEnergyLevel(), agents[1].GetEnergyLevel()}
+
+	var lootbox objects.ILootBox
+	for _, lootbox = range s.GetLootBoxes() {
+		break
+	}
+	state := bike.GetPhysicalState()
+	state.Position = lootbox.GetPosition()
+	bike.SetPhysicalState(state)
+	s.LootboxCheckAndDistributions()
+
+	// only the rider that pedalled shares the loot
+	assert.Greater(t, agents[0].GetEnergyLevel(), before[0])
+	assert.InDelta(t, before[1], agents[1].GetEnergyLevel(), utils.Epsilon)
+}
@@ -0,0 +1,40 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectionVotesAreAnalysed(t *testing.T) {
+	s := server.Initialize(0)
+	agents := []*ShoppingAgent{NewShoppingAgent(0), NewShoppingAgent(0), NewShoppingAgent(0)}
+	bike := seatShoppers(s, agents...)
+	s.UpdateGameStates()
+	weights := make(map[uuid.UUID]float64)
+	for _, agent := range agents {
+		weights[agent.GetID()] = 1.0
+	}
+
+	s.SetVoteAnalysis(false)
+	s.RunDemocraticAction(bike, weights)
+	assert.Empty(t, s.GetVoteAnalyses())
+
+	s.SetVoteAnalysis(true)
+	direction := s.RunDemocraticAction(bike, weights)
+
+	analyses := s.GetVoteAnalyses()
+	assert.Len(t, analyses, 1)
+	assert.Equal(t, utils.Direction, analyses[0].Action)
+	assert.Equal(t, bike.GetID(), analyses[0].BikeID)
+	assert.Equal(t, direction, analyses[0].Winner)
+
+	dump := s.NewGameStateDump(0)
+	assert.Len(t, dump.VoteAnalyses, 1)
+	statistics := server.CalculateStatistics([][]server.GameStateDump{{dump}})
+	assert.Equal(t, 1, statistics.Votes[0].Votes)
+	assert.Contains(t, statistics.PerRound[0].AgentManipulations, agents[0].GetID())
+}
@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MethodicalAgent struct {
+	*ShoppingAgent
+	methods map[utils.Action]utils.VoteMethod
+}
+
+func (a *MethodicalAgent) DecideVoteMethod(action utils.Action) utils.VoteMethod {
+	if method, ok := a.methods[action]; ok {
+		return method
+	}
+	return utils.VoteAction
+}
+
+func TestVoteMethodChangesWithMajority(t *testing.T) {
+	s := server.Initialize(0)
+	bike := seatShoppers(s)
+	for i := 0; i < 4; i++ {
+		agent := &MethodicalAgent{ShoppingAgent: NewShoppingAgent(0), methods: map[utils.Action]utils.VoteMethod{}}
+		if i < 3 {
+			agent.methods[utils.Direction] = utils.BORDACOUNT
+		}
+		if i < 1 {
+			agent.methods[utils.Election] = utils.INSTANTRUNOFF
+		}
+		s.AddAgent(agent)
+		agent.SetBike(bike.GetID())
+		s.AddAgentToBike(agent)
+	}
+
+	s.RunVoteMethodVote(bike)
+
+	// three quarters of the riders are enough to switch, a quarter isn't
+	assert.Equal(t, utils.BORDACOUNT, bike.GetVoteMethod(utils.Direction))
+	assert.Equal(t, utils.VoteAction, bike.GetVoteMethod(utils.Election))
+	events := s.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, server.VoteMethodChangeEvent, events[0].Type)
+
+	dump := s.NewGameStateDump(0).Bikes[bike.GetID()]
+	assert.Equal(t, utils.BORDACOUNT, dump.VoteMethods[utils.Direction])
+	assert.Equal(t, utils.BORDACOUNT, dump.GetVoteMethod(utils.Direction))
+}
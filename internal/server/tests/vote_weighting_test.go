@@ -0,0 +1,83 @@
+package server_test
+
+import (
+	"SOMAS2023/internal/common/utils"
+	"SOMAS2023/internal/server"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReputationWeighting(t *testing.T) {
+	s := server.Initialize(0)
+	agents := []*ShoppingAgent{NewShoppingAgent(0), NewShoppingAgent(0), NewShoppingAgent(0)}
+	bike := seatShoppers(s, agents...)
+	bike.SetWeightingPolicy(utils.ReputationWeighting)
+	// only the first agent has a good reputation with its fellow riders
+	agents[1].SetReputation(agents[0].GetID(), 1.0)
+	agents[2].SetReputation(agents[0].GetID(), 0.5)
+	agents[0].SetReputation(agents[1].GetID(), 0.0)
+
+	dump := s.NewGameStateDump(0).Bikes[bike.GetID()]
+	weights := dump.VoteWeights
+
+	assert.Equal(t, utils.ReputationWeighting, dump.WeightingPolicy)
+	assert.InDelta(t, 3.0, weights[agents[0].GetID()], utils.Epsilon)
+	assert.InDelta(t, 0.0, weights[agents[1].GetID()], utils.Epsilon)
+	assert.InDelta(t, 0.0, weights[agents[2].GetID()], utils.Epsilon)
+}
+
+func TestContributionWeightingBeforeAnyPedalling(t *testing.T) {
+	s := server.Initialize(0)
+	bike, agents := setupRules(s, utils.Democracy, 2)
+	bike.SetWeightingPolicy(utils.ContributionWeighting)
+
+	// nobody has pedalled yet, so every vote counts the same
+	weights := s.NewGameStateDump(0).Bikes[bike.GetID()].VoteWeights
+	for _, agent := range agents {
+		assert.Equal(t, 1.0, weights[agent.GetID()])
+	}
+
+	// once they have, the rider that pedalled harder has the heavier vote
+	agents[0].pedal = 1.0
+	agents[1].pedal = 0.5
+	s.RunActionProcess()
+
+	weights = s.NewGameStateDump(0).Bikes[bike.GetID()].VoteWeights
+	assert.Greater(t, weights[agents[0].GetID()], weights[agents[1].GetID()])
+	assert.Greater(t, weights[agents[1].GetID()], 0.0)
+	assert.InDelta(t, float64(len(agents)), weights[agents[0].GetID()]+weights[agents[1].GetID()], utils.Epsilon)
+}
+
+func TestSeniorityWeighting(t *testing.T) {
+	s := server.Initialize(0)
+	// the other agents could join the bike and kick the veteran out
+	for _, agent := range s.GetAgentMap() {
+		s.RemoveAgent(agent)
+	}
+	veteran := NewShoppingAgent(0)
+	bike := seatShoppers(s, veteran)
+	bike.SetWeightingPolicy(utils.SeniorityWeighting)
+	// and the Audi starts as far away from the bike as it can
+	audi := s.GetAudi().GetPhysicalState()
+	audi.Position = utils.Coordinates{X: 0, Y: 0}
+	if bike.GetPosition().X < utils.GridWidth/2 {
+		audi.Position.X = utils.GridWidth
+	}
+	if bike.GetPosition().Y < utils.GridHeight/2 {
+		audi.Position.Y = utils.GridHeight
+	}
+	s.GetAudi().SetPhysicalState(audi)
+	s.UpdateGameStates()
+	s.(*server.Server).RunRoundLoop()
+
+	// the veteran has been on the bike for two rounds, the newcomer for one
+	newcomer := NewShoppingAgent(0)
+	s.AddAgent(newcomer)
+	newcomer.SetBike(bike.GetID())
+	s.AddAgentToBike(newcomer)
+	weights := s.NewGameStateDump(0).Bikes[bike.GetID()].VoteWeights
+
+	assert.InDelta(t, 4.0/3.0, weights[veteran.GetID()], utils.Epsilon)
+	assert.InDelta(t, 2.0/3.0, weights[newcomer.GetID()], utils.Epsilon)
+}